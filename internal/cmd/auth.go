@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/kintone"
+)
+
+// resolveAuth は利用可能な認証情報を以下の優先順位で選択する
+//
+//  1. .env の KCDEV_API_TOKEN
+//  2. .env の KCDEV_USERNAME / KCDEV_PASSWORD
+//  3. .kcdev/config.json の auth.apiToken
+//  4. .kcdev/config.json の auth.username / auth.password
+func resolveAuth(projectDir string, cfg *config.Config) (kintone.Auth, error) {
+	envCfg, _ := config.LoadEnv(projectDir)
+	if envCfg != nil {
+		if envCfg.APIToken != "" {
+			return kintone.Auth{APIToken: envCfg.APIToken}, nil
+		}
+		if envCfg.HasPassword() {
+			return kintone.Auth{Username: envCfg.Username, Password: envCfg.Password}, nil
+		}
+	}
+
+	if token := config.NormalizeAPIToken(cfg.Kintone.Auth.APIToken); token != "" {
+		return kintone.Auth{APIToken: token}, nil
+	}
+	if cfg.Kintone.Auth.HasPassword() {
+		return kintone.Auth{Username: cfg.Kintone.Auth.Username, Password: cfg.Kintone.Auth.Password}, nil
+	}
+
+	return kintone.Auth{}, fmt.Errorf("認証情報が見つかりません。.env または .kcdev/config.json に設定してください")
+}
+
+// newClient は設定から kintone クライアントを生成する
+func newClient(projectDir string, cfg *config.Config) (*kintone.Client, error) {
+	auth, err := resolveAuth(projectDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := clientOptions(projectDir, cfg)
+	opts.Auth = auth
+	return kintone.NewClient(opts)
+}
+
+// clientOptions は Basic 認証・クライアント証明書・プロキシなどの接続設定を組み立てる
+// パスワード類は .env の値を優先する
+func clientOptions(projectDir string, cfg *config.Config) kintone.ClientOptions {
+	envCfg, _ := config.LoadEnv(projectDir)
+	if envCfg == nil {
+		envCfg = &config.EnvConfig{}
+	}
+
+	k := cfg.Kintone
+	opts := kintone.ClientOptions{
+		Domain:       k.Domain,
+		GuestSpaceID: k.GuestSpaceID,
+		CAFile:       resolvePath(projectDir, k.CAFile),
+	}
+
+	if envCfg.BasicAuthUsername != "" {
+		opts.BasicAuth = &kintone.BasicAuth{Username: envCfg.BasicAuthUsername, Password: envCfg.BasicAuthPassword}
+	} else if k.BasicAuth != nil && k.BasicAuth.Username != "" {
+		opts.BasicAuth = &kintone.BasicAuth{Username: k.BasicAuth.Username, Password: k.BasicAuth.Password}
+		if envCfg.BasicAuthPassword != "" {
+			opts.BasicAuth.Password = envCfg.BasicAuthPassword
+		}
+	}
+
+	if k.ClientCert != nil && k.ClientCert.File != "" {
+		opts.ClientCert = &kintone.ClientCert{
+			File:     resolvePath(projectDir, k.ClientCert.File),
+			KeyFile:  resolvePath(projectDir, k.ClientCert.KeyFile),
+			Password: k.ClientCert.Password,
+		}
+		if envCfg.ClientCertPassword != "" {
+			opts.ClientCert.Password = envCfg.ClientCertPassword
+		}
+	}
+
+	if k.Proxy != nil && k.Proxy.URL != "" {
+		opts.Proxy = &kintone.Proxy{URL: k.Proxy.URL, Username: k.Proxy.Username, Password: k.Proxy.Password}
+		if envCfg.ProxyPassword != "" {
+			opts.Proxy.Password = envCfg.ProxyPassword
+		}
+	}
+
+	if k.Retry != nil {
+		retry := kintone.DefaultRetryPolicy
+		retry.MaxRetries = k.Retry.MaxRetries
+		if k.Retry.BaseDelayMs > 0 {
+			retry.BaseDelay = time.Duration(k.Retry.BaseDelayMs) * time.Millisecond
+		}
+		if k.Retry.MaxDelayMs > 0 {
+			retry.MaxDelay = time.Duration(k.Retry.MaxDelayMs) * time.Millisecond
+		}
+		opts.Retry = &retry
+	}
+
+	return opts
+}
+
+// resolvePath はプロジェクトルートからの相対パスを絶対パスに変換する
+func resolvePath(projectDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectDir, path)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/generator"
+	"github.com/kintone/kcdev/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var exportCA string
+var renewCert bool
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "開発サーバーの証明書とローカル CA を管理",
+	Long: `開発サーバーの証明書は、ユーザーごとのローカル CA で発行します。
+ローカル CA を OS やブラウザに一度信頼させれば、すべてのプロジェクトで証明書の警告が出なくなります。`,
+	RunE: runCert,
+}
+
+func init() {
+	certCmd.Flags().StringVar(&exportCA, "export", "", "ローカル CA の証明書を書き出すファイル（.der / .cer の場合は DER 形式）")
+	certCmd.Flags().BoolVar(&renewCert, "renew", false, "プロジェクトの証明書を発行し直す")
+}
+
+func runCert(cmd *cobra.Command, args []string) error {
+	caCert, _, state, err := generator.EnsureCA()
+	if err != nil {
+		return fmt.Errorf("ローカル CA の準備に失敗しました: %w", err)
+	}
+	printCAState(state, caCert)
+
+	if exportCA != "" {
+		if err := generator.ExportCA(exportCA); err != nil {
+			return fmt.Errorf("ローカル CA の書き出しに失敗しました: %w", err)
+		}
+		ui.Success(fmt.Sprintf("ローカル CA の証明書を %s に書き出しました", exportCA))
+		return nil
+	}
+
+	infoStyle := lipgloss.NewStyle().Foreground(ui.ColorCyan)
+	caPath, _ := generator.CACertPath()
+
+	fmt.Println()
+	fmt.Println(infoStyle.Render("ローカル CA:"))
+	fmt.Printf("  ファイル: %s\n", caPath)
+	fmt.Printf("  有効期限: %s\n", caCert.NotAfter.Local().Format("2006-01-02"))
+
+	// プロジェクト内で実行した場合は、プロジェクトの証明書も表示する
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if cfg, err := config.Load(projectDir); err == nil {
+		if renewCert {
+			if err := generator.RegenerateCerts(projectDir, cfg.Dev.GetCertHosts()...); err != nil {
+				return fmt.Errorf("証明書生成エラー: %w", err)
+			}
+			ui.Success("証明書を発行し直しました")
+		}
+
+		fmt.Println()
+		fmt.Println(infoStyle.Render("プロジェクトの証明書:"))
+		if cert, err := generator.LoadCert(projectDir); err != nil {
+			fmt.Println("  なし（kcdev dev の起動時に発行されます）")
+		} else {
+			var sans []string
+			sans = append(sans, cert.DNSNames...)
+			for _, ip := range cert.IPAddresses {
+				sans = append(sans, ip.String())
+			}
+			fmt.Printf("  SAN:      %s\n", strings.Join(sans, ", "))
+			fmt.Printf("  有効期限: %s\n", cert.NotAfter.Local().Format("2006-01-02"))
+			if renew, reason := generator.CertNeedsRenewal(projectDir, cfg.Dev.GetCertHosts()); renew {
+				fmt.Printf("  状態:     %s（kcdev dev の起動時に発行し直します）\n", reason)
+			}
+		}
+	} else if renewCert {
+		return fmt.Errorf("設定ファイルが見つかりません。kcdev init を実行してください: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println(infoStyle.Render("ローカル CA を信頼させる方法:"))
+	for _, line := range caTrustSteps(caPath) {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println()
+	return nil
+}
+
+// caTrustSteps は OS ごとにローカル CA を信頼させる手順を返す
+func caTrustSteps(caPath string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			fmt.Sprintf("sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain \"%s\"", caPath),
+		}
+	case "windows":
+		return []string{
+			fmt.Sprintf("certutil -user -addstore Root \"%s\"", caPath),
+		}
+	default:
+		return []string{
+			fmt.Sprintf("sudo cp \"%s\" /usr/local/share/ca-certificates/kcdev-rootCA.crt && sudo update-ca-certificates", caPath),
+			"Chrome / Firefox は各ブラウザの証明書設定から「認証局」としてインポートしてください",
+		}
+	}
+}
+
+// printCAState はローカル CA を作成・作り直したことと、信頼させる必要があることを表示する
+func printCAState(state generator.CAState, caCert *x509.Certificate) {
+	caPath, _ := generator.CACertPath()
+	switch state {
+	case generator.CACreated:
+		ui.Success(fmt.Sprintf("ローカル CA を作成しました（%s）", caPath))
+		ui.Info("ブラウザの証明書の警告をなくすには、ローカル CA を信頼させてください（手順は kcdev cert で表示）")
+	case generator.CARenewed:
+		ui.Warn(fmt.Sprintf("ローカル CA の有効期限が近いため、新しいローカル CA を作成しました（%s まで有効）", caCert.NotAfter.Local().Format("2006-01-02")))
+		ui.Warn("新しいローカル CA を OS やブラウザに信頼させ直してください（手順は kcdev cert で表示）。各プロジェクトの証明書は kcdev dev の起動時に発行し直されます")
+		ui.Info(fmt.Sprintf("古いローカル CA は %s.old に残しています。信頼の設定から削除して構いません", caPath))
+	}
+}
+
+// ensureDevCerts はローカル CA を準備し、開発サーバーの証明書が無い・期限が近い・
+// hosts に対応していない場合は発行し直す
+func ensureDevCerts(projectDir string, hosts []string) error {
+	caCert, _, state, err := generator.EnsureCA()
+	if err != nil {
+		return fmt.Errorf("ローカル CA の準備に失敗しました: %w", err)
+	}
+	printCAState(state, caCert)
+
+	renew, reason := generator.CertNeedsRenewal(projectDir, hosts)
+	if !renew {
+		return nil
+	}
+	ui.Info(fmt.Sprintf("%s。証明書を発行し直します", reason))
+	if err := generator.RegenerateCerts(projectDir, hosts...); err != nil {
+		return fmt.Errorf("証明書生成エラー: %w", err)
+	}
+	if cert, err := generator.LoadCert(projectDir); err == nil {
+		ui.Success(fmt.Sprintf("証明書を発行しました（%s まで有効）", cert.NotAfter.Local().Format("2006-01-02")))
+	}
+	return nil
+}
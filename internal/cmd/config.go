@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -66,6 +67,20 @@ func runConfig(cmd *cobra.Command, args []string) error {
 			if err := cfg.Save(cwd); err != nil {
 				return err
 			}
+			// リダイレクト先のアプリ URL が変わるため、保存した設定で開発用ページを再生成
+			if err := generator.GenerateIndexHTML(cwd, cfg.Kintone.AppURL()); err != nil {
+				return fmt.Errorf("index.html 再生成エラー: %w", err)
+			}
+		case "connection":
+			if err := editConnectionOptions(cfg); err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					continue
+				}
+				return err
+			}
+			if err := cfg.Save(cwd); err != nil {
+				return err
+			}
 		case "targets":
 			if err := editTargets(cfg); err != nil {
 				if errors.Is(err, huh.ErrUserAborted) {
@@ -86,6 +101,46 @@ func runConfig(cmd *cobra.Command, args []string) error {
 			if err := cfg.Save(cwd); err != nil {
 				return err
 			}
+		case "deploy":
+			if err := editDeployMode(cfg); err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					continue
+				}
+				return err
+			}
+			if err := cfg.Save(cwd); err != nil {
+				return err
+			}
+		case "libraries":
+			if err := editLibraries(cfg); err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					continue
+				}
+				return err
+			}
+			if err := cfg.Save(cwd); err != nil {
+				return err
+			}
+		case "server":
+			if err := editDevServer(cwd, cfg); err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					continue
+				}
+				return err
+			}
+			if err := cfg.Save(cwd); err != nil {
+				return err
+			}
+		case "loader":
+			if err := editDevLoader(cwd, cfg); err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					continue
+				}
+				return err
+			}
+			if err := cfg.Save(cwd); err != nil {
+				return err
+			}
 		case "output":
 			if err := editOutput(cwd, cfg); err != nil {
 				if errors.Is(err, huh.ErrUserAborted) {
@@ -132,8 +187,13 @@ func askConfigAction() (string, error) {
 				Options(
 					huh.NewOption("現在の設定を表示", "view"),
 					huh.NewOption("kintone接続設定（ドメイン、アプリID、認証）", "kintone"),
+					huh.NewOption("接続オプション（Basic認証、クライアント証明書、プロキシ）", "connection"),
 					huh.NewOption("ターゲット（デスクトップ/モバイル）の設定", "targets"),
 					huh.NewOption("適用範囲の設定", "scope"),
+					huh.NewOption("デプロイモード（上書き/マージ）の設定", "deploy"),
+					huh.NewOption("外部ライブラリ（CDN / ベンダーファイル）の管理", "libraries"),
+					huh.NewOption("開発サーバー（ホスト、ポート、証明書）の設定", "server"),
+					huh.NewOption("dev ローダーの設定（モード、形式、対象ユーザー、フォールバック、ログ転送）", "loader"),
 					huh.NewOption("出力ファイル名の設定", "output"),
 					huh.NewOption("エントリーファイルの設定", "entry"),
 					huh.NewOption("フレームワークの変更", "framework"),
@@ -162,13 +222,32 @@ func showCurrentConfig(cfg *config.Config) {
 	fmt.Println(infoStyle.Render("kintone:"))
 	fmt.Printf("  ドメイン:   %s\n", cfg.Kintone.Domain)
 	fmt.Printf("  アプリID:   %d\n", cfg.Kintone.AppID)
-	if cfg.Kintone.Auth.Username != "" {
+	if cfg.Kintone.GuestSpaceID > 0 {
+		fmt.Printf("  ゲストスペース: %d\n", cfg.Kintone.GuestSpaceID)
+	}
+	if cfg.Kintone.Auth.APIToken != "" {
+		tokens := strings.Split(cfg.Kintone.Auth.APIToken, ",")
+		fmt.Printf("  認証:       API トークン (%d件)\n", len(tokens))
+	} else if cfg.Kintone.Auth.Username != "" {
 		fmt.Printf("  ユーザー:   %s\n", cfg.Kintone.Auth.Username)
 		fmt.Printf("  パスワード: %s\n", "********")
 	} else {
 		fmt.Printf("  認証:       %s\n", warnStyle.Render("未設定"))
 	}
 
+	if cfg.Kintone.BasicAuth != nil {
+		fmt.Printf("  Basic認証:  %s\n", cfg.Kintone.BasicAuth.Username)
+	}
+	if cfg.Kintone.ClientCert != nil {
+		fmt.Printf("  証明書:     %s\n", cfg.Kintone.ClientCert.File)
+	}
+	if cfg.Kintone.CAFile != "" {
+		fmt.Printf("  CA:         %s\n", cfg.Kintone.CAFile)
+	}
+	if cfg.Kintone.Proxy != nil {
+		fmt.Printf("  プロキシ:   %s\n", cfg.Kintone.Proxy.URL)
+	}
+
 	// ターゲット
 	fmt.Println()
 	fmt.Println(infoStyle.Render("ターゲット:"))
@@ -197,6 +276,32 @@ func showCurrentConfig(cfg *config.Config) {
 		fmt.Printf("  %s すべてのユーザー (ALL)\n", successStyle.Render("✓"))
 	}
 
+	// デプロイモード
+	fmt.Println()
+	fmt.Println(infoStyle.Render("デプロイモード:"))
+	if cfg.GetDeployMode() == config.DeployModeMerge {
+		fmt.Printf("  %s マージ（既存のカスタマイズを維持）\n", successStyle.Render("✓"))
+		if cfg.Deploy.Position != nil {
+			if len(cfg.Deploy.Position.After) > 0 {
+				fmt.Printf("  後ろに配置: %s\n", strings.Join(cfg.Deploy.Position.After, ", "))
+			}
+			if len(cfg.Deploy.Position.Before) > 0 {
+				fmt.Printf("  前に配置:   %s\n", strings.Join(cfg.Deploy.Position.Before, ", "))
+			}
+		}
+	} else {
+		fmt.Printf("  %s 上書き\n", successStyle.Render("✓"))
+	}
+
+	// 外部ライブラリ
+	if !cfg.Libraries.IsEmpty() {
+		fmt.Println()
+		fmt.Println(infoStyle.Render("外部ライブラリ:"))
+		for _, item := range libraryItems(cfg) {
+			fmt.Printf("  %s\n", item.label())
+		}
+	}
+
 	// 出力ファイル名
 	fmt.Println()
 	fmt.Println(infoStyle.Render("出力:"))
@@ -205,14 +310,29 @@ func showCurrentConfig(cfg *config.Config) {
 	// Dev設定
 	fmt.Println()
 	fmt.Println(infoStyle.Render("開発サーバー:"))
-	fmt.Printf("  オリジン:   %s\n", cfg.Dev.Origin)
+	fmt.Printf("  オリジン:   %s\n", cfg.Dev.DevOrigin())
 	fmt.Printf("  エントリー: %s\n", cfg.Dev.Entry)
+	fmt.Printf("  モード:     %s\n", cfg.Dev.GetMode())
+	fmt.Printf("  ローダー:   スキーマ %d\n", cfg.Dev.GetLoaderSchemaVersion())
+	if cfg.Dev.FallbackOnError {
+		fmt.Printf("  接続できない場合: 最後にデプロイしたバンドルを適用\n")
+	}
+	if !cfg.Dev.GetForwardLogs() {
+		fmt.Printf("  ブラウザのログ: 転送しない\n")
+	}
+	if len(cfg.Dev.Users) > 0 {
+		fmt.Printf("  対象ユーザー: %s\n", strings.Join(cfg.Dev.Users, ", "))
+	} else {
+		fmt.Printf("  対象ユーザー: すべて\n")
+	}
 
 	fmt.Println()
 	fmt.Println("Enterキーで戻る...")
 	fmt.Scanln()
 }
 
+// editKintoneConfig は kintone の接続設定を尋ねる
+// 途中で中断した場合に一部の設定だけが変わらないよう、すべて入力してから cfg に反映する
 func editKintoneConfig(cfg *config.Config) error {
 	fmt.Println()
 	ui.Title("kintone接続設定")
@@ -223,14 +343,18 @@ func editKintoneConfig(cfg *config.Config) error {
 	if err != nil {
 		return err
 	}
-	cfg.Kintone.Domain = domain
 
 	// アプリID
 	appID, err := prompt.AskAppID(cfg.Kintone.AppID)
 	if err != nil {
 		return err
 	}
-	cfg.Kintone.AppID = appID
+
+	// ゲストスペースID
+	guestSpaceID, err := prompt.AskGuestSpaceID(cfg.Kintone.GuestSpaceID)
+	if err != nil {
+		return err
+	}
 
 	// 認証情報を更新するか確認
 	var updateAuth bool
@@ -247,24 +371,465 @@ func editKintoneConfig(cfg *config.Config) error {
 		return err
 	}
 
+	auth := cfg.Kintone.Auth
 	if updateAuth {
-		username, err := prompt.AskUsername()
+		method, err := prompt.AskAuthMethod()
+		if err != nil {
+			return err
+		}
+
+		if method == prompt.AuthMethodAPIToken {
+			token, err := prompt.AskAPIToken()
+			if err != nil {
+				return err
+			}
+			auth = config.AuthConfig{APIToken: config.NormalizeAPIToken(token)}
+		} else {
+			username, err := prompt.AskUsername()
+			if err != nil {
+				return err
+			}
+			password, err := prompt.AskPassword()
+			if err != nil {
+				return err
+			}
+			auth = config.AuthConfig{Username: username, Password: password}
+		}
+	}
+
+	cfg.Kintone.Domain = domain
+	cfg.Kintone.AppID = appID
+	cfg.Kintone.GuestSpaceID = guestSpaceID
+	cfg.Kintone.Auth = auth
+
+	fmt.Println()
+	ui.Success("kintone接続設定を更新しました")
+	return nil
+}
+
+func editConnectionOptions(cfg *config.Config) error {
+	fmt.Println()
+	ui.Title("接続オプション")
+	fmt.Println()
+
+	var kind string
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("設定する項目を選択").
+				Options(
+					huh.NewOption("Basic認証", "basic"),
+					huh.NewOption("クライアント証明書（セキュアアクセス）", "cert"),
+					huh.NewOption("CA証明書バンドル", "ca"),
+					huh.NewOption("プロキシ", "proxy"),
+				).
+				Value(&kind),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	ui.Info("空欄のまま確定すると設定を削除します（パスワードは空欄のままにすると現在の値を引き継ぎます）")
+	fmt.Println()
+
+	switch kind {
+	case "basic":
+		var username, password, current string
+		if cfg.Kintone.BasicAuth != nil {
+			username = cfg.Kintone.BasicAuth.Username
+			current = cfg.Kintone.BasicAuth.Password
+		}
+		err = ui.NewForm(
+			huh.NewGroup(
+				huh.NewInput().Title("Basic認証 ユーザー名").Value(&username),
+				huh.NewInput().
+					Title("Basic認証 パスワード").
+					Description(passwordDescription(current)).
+					EchoMode(huh.EchoModePassword).
+					Value(&password),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+		if username == "" {
+			cfg.Kintone.BasicAuth = nil
+		} else {
+			cfg.Kintone.BasicAuth = &config.BasicAuthConfig{Username: username, Password: keepPassword(password, current)}
+		}
+
+	case "cert":
+		var file, keyFile, password, current string
+		if cfg.Kintone.ClientCert != nil {
+			file = cfg.Kintone.ClientCert.File
+			keyFile = cfg.Kintone.ClientCert.KeyFile
+			current = cfg.Kintone.ClientCert.Password
+		}
+		err = ui.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("証明書ファイル").
+					Description("PKCS#12 (.pfx/.p12) または PEM。プロジェクトルートからの相対パス可").
+					Value(&file),
+				huh.NewInput().
+					Title("秘密鍵ファイル (PEM の場合のみ)").
+					Value(&keyFile),
+				huh.NewInput().
+					Title("証明書のパスワード (PKCS#12 の場合)").
+					Description(passwordDescription(current)).
+					EchoMode(huh.EchoModePassword).
+					Value(&password),
+			),
+		).Run()
 		if err != nil {
 			return err
 		}
-		password, err := prompt.AskPassword()
+		if file == "" {
+			cfg.Kintone.ClientCert = nil
+		} else {
+			cfg.Kintone.ClientCert = &config.ClientCertConfig{File: file, KeyFile: keyFile, Password: keepPassword(password, current)}
+		}
+
+	case "ca":
+		caFile := cfg.Kintone.CAFile
+		err = ui.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("CA証明書バンドル (PEM)").
+					Value(&caFile),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+		cfg.Kintone.CAFile = caFile
+
+	case "proxy":
+		var proxyURL, username, password, current string
+		if cfg.Kintone.Proxy != nil {
+			proxyURL = cfg.Kintone.Proxy.URL
+			username = cfg.Kintone.Proxy.Username
+			current = cfg.Kintone.Proxy.Password
+		}
+		err = ui.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("プロキシ URL").
+					Description("例: http://proxy.example.com:8080").
+					Value(&proxyURL),
+				huh.NewInput().Title("プロキシ ユーザー名 (任意)").Value(&username),
+				huh.NewInput().
+					Title("プロキシ パスワード (任意)").
+					Description(passwordDescription(current)).
+					EchoMode(huh.EchoModePassword).
+					Value(&password),
+			),
+		).Run()
 		if err != nil {
 			return err
 		}
-		cfg.Kintone.Auth.Username = username
-		cfg.Kintone.Auth.Password = password
+		if proxyURL == "" {
+			cfg.Kintone.Proxy = nil
+		} else {
+			cfg.Kintone.Proxy = &config.ProxyConfig{URL: proxyURL, Username: username, Password: keepPassword(password, current)}
+		}
 	}
 
 	fmt.Println()
-	ui.Success("kintone接続設定を更新しました")
+	ui.Success("接続オプションを更新しました")
+	return nil
+}
+
+// passwordDescription は保存済みのパスワードがある場合に、空欄なら引き継ぐことを説明する
+func passwordDescription(current string) string {
+	if current == "" {
+		return ""
+	}
+	return "設定済み。変更しない場合は空欄のまま確定してください"
+}
+
+// keepPassword は入力が空欄の場合に保存済みのパスワードを返す
+// ユーザー名や URL だけを変更したときに、保存済みのパスワードを消さないようにする
+func keepPassword(input, current string) string {
+	if input == "" {
+		return current
+	}
+	return input
+}
+
+// libraryItem は設定済みライブラリの 1 件を表す
+type libraryItem struct {
+	target string // desktop / mobile
+	kind   string // js / css
+	index  int
+	lib    config.Library
+}
+
+func (i libraryItem) label() string {
+	target := "デスクトップ"
+	if i.target == "mobile" {
+		target = "モバイル"
+	}
+	position := "前"
+	if i.lib.IsAfter() {
+		position = "後"
+	}
+	return fmt.Sprintf("[%s %s #%d %s] %s", target, strings.ToUpper(i.kind), i.index+1, position, i.lib.Label())
+}
+
+// libraryItems は設定済みのライブラリを表示順に並べて返す
+func libraryItems(cfg *config.Config) []libraryItem {
+	var items []libraryItem
+	if cfg.Libraries == nil {
+		return items
+	}
+	for _, target := range []string{"desktop", "mobile"} {
+		for _, kind := range []string{"js", "css"} {
+			for i, lib := range *libraryList(cfg, target, kind) {
+				items = append(items, libraryItem{target: target, kind: kind, index: i, lib: lib})
+			}
+		}
+	}
+	return items
+}
+
+func libraryList(cfg *config.Config, target, kind string) *[]config.Library {
+	if cfg.Libraries == nil {
+		cfg.Libraries = &config.LibrariesConfig{}
+	}
+	set := &cfg.Libraries.Desktop
+	if target == "mobile" {
+		set = &cfg.Libraries.Mobile
+	}
+	if kind == "css" {
+		return &set.CSS
+	}
+	return &set.JS
+}
+
+func editLibraries(cfg *config.Config) error {
+	fmt.Println()
+	ui.Title("外部ライブラリ")
+	fmt.Println()
+
+	items := libraryItems(cfg)
+	if len(items) == 0 {
+		fmt.Println("  登録されたライブラリはありません")
+	}
+	for _, item := range items {
+		fmt.Printf("  %s\n", item.label())
+	}
+	fmt.Println()
+
+	actions := []huh.Option[string]{huh.NewOption("追加", "add")}
+	if len(items) > 0 {
+		actions = append(actions,
+			huh.NewOption("削除", "remove"),
+			huh.NewOption("順序を変更", "move"),
+		)
+	}
+
+	var action string
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("操作を選択").
+				Options(actions...).
+				Value(&action),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	options := make([]huh.Option[int], len(items))
+	for i, item := range items {
+		options[i] = huh.NewOption(item.label(), i)
+	}
+
+	switch action {
+	case "add":
+		if err := addLibrary(cfg); err != nil {
+			return err
+		}
+
+	case "remove":
+		var selected []int
+		err := ui.NewForm(
+			huh.NewGroup(
+				huh.NewMultiSelect[int]().
+					Title("削除するライブラリを選択").
+					Options(options...).
+					Value(&selected),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+		// インデックスがずれないよう後ろから削除する
+		for i := len(items) - 1; i >= 0; i-- {
+			if !containsInt(selected, i) {
+				continue
+			}
+			list := libraryList(cfg, items[i].target, items[i].kind)
+			*list = append((*list)[:items[i].index], (*list)[items[i].index+1:]...)
+		}
+
+	case "move":
+		var selected int
+		err := ui.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[int]().
+					Title("移動するライブラリを選択").
+					Options(options...).
+					Value(&selected),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+
+		item := items[selected]
+		list := libraryList(cfg, item.target, item.kind)
+		positions := make([]huh.Option[int], len(*list))
+		for i, lib := range *list {
+			positions[i] = huh.NewOption(fmt.Sprintf("%d: %s", i+1, lib.Label()), i)
+		}
+		to := item.index
+		err = ui.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[int]().
+					Title("移動先の位置を選択").
+					Options(positions...).
+					Value(&to),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+
+		moved := append((*list)[:item.index:item.index], (*list)[item.index+1:]...)
+		*list = append(moved[:to], append([]config.Library{item.lib}, moved[to:]...)...)
+	}
+
+	if cfg.Libraries.IsEmpty() {
+		cfg.Libraries = nil
+	}
+
+	fmt.Println()
+	ui.Success("外部ライブラリを更新しました")
+	return nil
+}
+
+func addLibrary(cfg *config.Config) error {
+	var source, value, position string
+	targets := []string{}
+	if cfg.Targets.Desktop {
+		targets = append(targets, "desktop")
+	}
+	if cfg.Targets.Mobile {
+		targets = append(targets, "mobile")
+	}
+
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("種類").
+				Options(
+					huh.NewOption("URL（CDN など）", "url"),
+					huh.NewOption("プロジェクト内のファイル", "file"),
+				).
+				Value(&source),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	title := "URL"
+	description := "例: https://js.cybozu.com/jquery/3.7.1/jquery.min.js"
+	if source == "file" {
+		title = "ファイルパス"
+		description = "プロジェクトルートからの相対パス（例: vendor/sweetalert2.min.js）"
+	}
+
+	err = ui.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(title).
+				Description(description).
+				Validate(func(s string) error {
+					s = strings.TrimSpace(s)
+					if s == "" {
+						return fmt.Errorf("入力してください")
+					}
+					if source == "url" && !strings.HasPrefix(s, "https://") {
+						return fmt.Errorf("https:// で始まる URL を入力してください")
+					}
+					return nil
+				}).
+				Value(&value),
+			huh.NewSelect[string]().
+				Title("読み込み順").
+				Options(
+					huh.NewOption("kcdev のファイルより前", config.LibraryBefore),
+					huh.NewOption("kcdev のファイルより後", config.LibraryAfter),
+				).
+				Value(&position),
+			huh.NewMultiSelect[string]().
+				Title("対象").
+				Options(
+					huh.NewOption("デスクトップ", "desktop"),
+					huh.NewOption("モバイル", "mobile"),
+				).
+				Validate(func(s []string) error {
+					if len(s) == 0 {
+						return fmt.Errorf("少なくとも1つ選択してください")
+					}
+					return nil
+				}).
+				Value(&targets),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	value = strings.TrimSpace(value)
+	lib := config.Library{}
+	if source == "file" {
+		lib.File = value
+	} else {
+		lib.URL = value
+	}
+	if position == config.LibraryAfter {
+		lib.Position = config.LibraryAfter
+	}
+
+	// 拡張子で JS / CSS を判定する（クエリ文字列は無視）
+	kind := "js"
+	if strings.HasSuffix(strings.ToLower(strings.SplitN(value, "?", 2)[0]), ".css") {
+		kind = "css"
+	}
+
+	for _, target := range targets {
+		list := libraryList(cfg, target, kind)
+		*list = append(*list, lib)
+	}
 	return nil
 }
 
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
 func editTargets(cfg *config.Config) error {
 	fmt.Println()
 
@@ -301,6 +866,210 @@ func editScope(cfg *config.Config) error {
 	return nil
 }
 
+func editDeployMode(cfg *config.Config) error {
+	fmt.Println()
+
+	mode := cfg.GetDeployMode()
+	var after, before string
+	if cfg.Deploy != nil && cfg.Deploy.Position != nil {
+		after = strings.Join(cfg.Deploy.Position.After, ",")
+		before = strings.Join(cfg.Deploy.Position.Before, ",")
+	}
+
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("デプロイモード").
+				Options(
+					huh.NewOption("上書き（kcdev のファイルのみにする）", config.DeployModeOverwrite),
+					huh.NewOption("マージ（既存のカスタマイズを維持する）", config.DeployModeMerge),
+				).
+				Value(&mode),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	if mode == config.DeployModeMerge {
+		err := ui.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title("このファイルより後ろに配置（ファイル名/URL の一部、カンマ区切り）").
+					Placeholder("jquery").
+					Value(&after),
+				huh.NewInput().
+					Title("このファイルより前に配置（ファイル名/URL の一部、カンマ区切り）").
+					Value(&before),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg.Deploy = &config.DeployConfig{Mode: mode}
+	if mode == config.DeployModeMerge {
+		position := &config.PositionConfig{
+			After:  splitList(after),
+			Before: splitList(before),
+		}
+		if len(position.After) > 0 || len(position.Before) > 0 {
+			cfg.Deploy.Position = position
+		}
+	}
+
+	fmt.Println()
+	ui.Success("デプロイモードを更新しました")
+	return nil
+}
+
+// splitList はカンマ区切りの文字列を空要素を除いたスライスに変換する
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func editDevServer(projectDir string, cfg *config.Config) error {
+	fmt.Println()
+
+	host := cfg.Dev.GetHost()
+	port := strconv.Itoa(cfg.Dev.GetPort())
+	certHosts := strings.Join(cfg.Dev.CertHosts, ",")
+	sessionToken := cfg.Dev.SessionToken
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("ホスト名").
+				Description("kintone を開くブラウザから開発サーバーに接続するときのホスト名").
+				Value(&host).
+				Validate(config.ValidateDevHost),
+			huh.NewInput().
+				Title("ポート").
+				Description("複数のプロジェクトを同時に開発する場合は、プロジェクトごとに変えてください").
+				Value(&port).
+				Validate(func(s string) error {
+					_, err := config.ParseDevPort(s)
+					return err
+				}),
+			huh.NewInput().
+				Title("証明書に追加するホスト（カンマ区切り）").
+				Description("localhost と上のホスト名は常に含まれます。別名や IP アドレスで接続する場合に指定します").
+				Placeholder("kcdev.local,192.168.1.10").
+				Value(&certHosts),
+			huh.NewConfirm().
+				Title("バンドルの配信にセッショントークンを要求しますか?").
+				Description("kcdev dev の起動ごとにトークンを発行し、開発サーバーを開いたブラウザだけがコードを読み込めるようにします").
+				Affirmative("はい").
+				Negative("いいえ").
+				Value(&sessionToken),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	portNum, _ := config.ParseDevPort(port)
+	oldOrigin := cfg.Dev.DevOrigin()
+	cfg.Dev.SetServer(strings.TrimSpace(host), portNum)
+	cfg.Dev.CertHosts = splitList(certHosts)
+	cfg.Dev.SessionToken = sessionToken
+
+	// 証明書の SAN にホストが含まれない場合は発行し直す
+	if err := ensureDevCerts(projectDir, cfg.Dev.GetCertHosts()); err != nil {
+		return err
+	}
+
+	// 以前の Vite 設定はポートを固定しているため、設定を読み込む形式で生成し直す
+	if err := generator.GenerateViteConfig(projectDir, detectCurrentFramework(projectDir), detectCurrentLanguage(projectDir)); err != nil {
+		return fmt.Errorf("Vite設定生成エラー: %w", err)
+	}
+	if err := generator.GenerateIndexHTML(projectDir, cfg.Kintone.AppURL()); err != nil {
+		return fmt.Errorf("index.html 生成エラー: %w", err)
+	}
+	if err := generator.RegenerateLoader(projectDir, loaderOptions(projectDir, cfg)); err != nil {
+		return fmt.Errorf("ローダー再生成エラー: %w", err)
+	}
+
+	fmt.Println()
+	if cfg.Dev.DevOrigin() != oldOrigin {
+		ui.Success(fmt.Sprintf("開発サーバーを %s に変更しました（次回の kcdev dev でローダーを再デプロイします）", cfg.Dev.DevOrigin()))
+	} else {
+		ui.Success("開発サーバーの設定を更新しました")
+	}
+	return nil
+}
+
+func editDevLoader(projectDir string, cfg *config.Config) error {
+	fmt.Println()
+
+	mode := cfg.Dev.GetMode()
+	version := cfg.Dev.GetLoaderSchemaVersion()
+	users := strings.Join(cfg.Dev.Users, ",")
+	fallbackOnError := cfg.Dev.FallbackOnError
+	forwardLogs := cfg.Dev.GetForwardLogs()
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("開発モード").
+				Options(
+					huh.NewOption("IIFE（バンドルして変更時にリロード）", config.DevModeIIFE),
+					huh.NewOption("ESM（モジュールを直接読み込み、HMR で状態を保持）", config.DevModeESM),
+				).
+				Value(&mode),
+			huh.NewSelect[int]().
+				Title("ローダーの形式").
+				Options(
+					huh.NewOption("スキーマ 1（同期 XHR + eval）", config.LoaderSchemaV1),
+					huh.NewOption("スキーマ 2（script 要素で非同期に読み込み、CSP 対応）", config.LoaderSchemaV2),
+				).
+				Value(&version),
+			huh.NewInput().
+				Title("dev ローダーを適用するユーザー（ログイン名、カンマ区切り）").
+				Description("空欄の場合はすべてのユーザーに適用します。それ以外のユーザーには最後に kcdev deploy したバンドルが適用されます").
+				Placeholder("taro.yamada").
+				Value(&users),
+			huh.NewConfirm().
+				Title("開発サーバーに接続できない場合、最後に kcdev deploy したバンドルを適用しますか?").
+				Affirmative("はい").
+				Negative("いいえ").
+				Value(&fallbackOnError),
+			huh.NewConfirm().
+				Title("ブラウザの console 出力とエラーをターミナルに表示しますか?").
+				Affirmative("はい").
+				Negative("いいえ").
+				Value(&forwardLogs),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	// ESM モードは保留したイベントを後から処理できるスキーマ 2 でのみ動作する
+	if mode == config.DevModeESM && version < config.LoaderSchemaV2 {
+		ui.Info("ESM モードのため、ローダーの形式をスキーマ 2 にします")
+		version = config.LoaderSchemaV2
+	}
+	cfg.Dev.Mode = mode
+	cfg.Dev.LoaderSchemaVersion = version
+	cfg.Dev.Users = splitList(users)
+	cfg.Dev.FallbackOnError = fallbackOnError
+	cfg.Dev.ForwardLogs = &forwardLogs
+
+	// ローダーを再生成
+	if err := generator.RegenerateLoader(projectDir, loaderOptions(projectDir, cfg)); err != nil {
+		return fmt.Errorf("ローダー再生成エラー: %w", err)
+	}
+
+	ui.Success("dev ローダーの設定を更新しました（次回の kcdev dev から反映されます）")
+	return nil
+}
+
 func editOutput(projectDir string, cfg *config.Config) error {
 	fmt.Println()
 
@@ -312,9 +1081,7 @@ func editOutput(projectDir string, cfg *config.Config) error {
 	cfg.Output = output
 
 	// ローダーを再生成
-	currentFramework := detectCurrentFramework(projectDir)
-	currentLanguage := detectCurrentLanguage(projectDir)
-	if err := generator.RegenerateLoader(projectDir, currentFramework, currentLanguage, output, cfg.GetOutputName(), cfg.Kintone.Domain, cfg.Kintone.AppID); err != nil {
+	if err := generator.RegenerateLoader(projectDir, loaderOptions(projectDir, cfg)); err != nil {
 		return fmt.Errorf("ローダー再生成エラー: %w", err)
 	}
 
@@ -499,7 +1266,9 @@ func editFramework(projectDir string, cfg *config.Config) error {
 
 	// 5. ローダーを再生成
 	err = ui.SpinnerWithResult("ローダーを再生成中...", func() error {
-		return generator.RegenerateLoader(projectDir, newFramework, currentLanguage, cfg.Output, cfg.GetOutputName(), cfg.Kintone.Domain, cfg.Kintone.AppID)
+		opts := loaderOptions(projectDir, cfg)
+		opts.Framework = newFramework
+		return generator.RegenerateLoader(projectDir, opts)
 	})
 	if err != nil {
 		return fmt.Errorf("ローダー再生成エラー: %w", err)
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/snapshot"
+	"github.com/kintone/kcdev/internal/ui"
+)
+
+// customizeUpload は kintone にアップロードするファイル
+type customizeUpload struct {
+	JSPath string
+	// CSSPath が空の場合は CSS をアップロードしない
+	CSSPath string
+}
+
+type deployOptions struct {
+	// Command はバックアップに記録するコマンド名（deploy / dev）
+	Command     string
+	Title       string
+	Mode        string
+	PreviewOnly bool
+}
+
+// resolveDeployMode はフラグ、設定ファイルの順にデプロイモードを決定する
+func resolveDeployMode(cfg *config.Config, flag string) (string, error) {
+	mode := flag
+	if mode == "" {
+		mode = cfg.GetDeployMode()
+	}
+	switch mode {
+	case config.DeployModeOverwrite, config.DeployModeMerge:
+		return mode, nil
+	}
+	return "", fmt.Errorf("不明なデプロイモードです: %s（overwrite / merge を指定してください）", mode)
+}
+
+// managedFileNames は kcdev が管理するファイル名・URL の一覧（libraries に登録したものを含む）
+func managedFileNames(cfg *config.Config) []string {
+	outputName := cfg.GetOutputName()
+	names := []string{outputName + ".js", outputName + ".css", "kintone-dev-loader.js"}
+
+	if cfg.Libraries != nil {
+		for _, set := range []config.LibrarySet{cfg.Libraries.Desktop, cfg.Libraries.Mobile} {
+			for _, lib := range append(append([]config.Library{}, set.JS...), set.CSS...) {
+				if lib.URL != "" {
+					names = append(names, lib.URL)
+				} else if lib.File != "" {
+					names = append(names, filepath.Base(lib.File))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// confirmOverwrite は kcdev 管理外のカスタマイズがある場合に上書きしてよいか確認する
+// merge モードでは既存のカスタマイズが維持されるため確認しない
+func confirmOverwrite(ctx context.Context, client *kintone.Client, cfg *config.Config, mode string) (bool, error) {
+	existing, err := client.GetExistingCustomizations(ctx, cfg.Kintone.AppID, managedFileNames(cfg))
+	if err != nil {
+		ui.Warn(fmt.Sprintf("既存カスタマイズの確認をスキップ: %v", err))
+		return true, nil
+	}
+	if !existing.HasExisting() {
+		return true, nil
+	}
+
+	if mode == config.DeployModeMerge {
+		ui.Info(fmt.Sprintf("既存のカスタマイズを維持してデプロイします（%s）", existing.Summary()))
+		return true, nil
+	}
+
+	fmt.Println()
+	ui.Warn("既存のカスタマイズが検出されました:")
+
+	// 詳細を表示
+	if len(existing.Desktop.JS) > 0 {
+		fmt.Printf("    デスクトップ JS: %s\n", strings.Join(existing.Desktop.JS, ", "))
+	}
+	if len(existing.Desktop.CSS) > 0 {
+		fmt.Printf("    デスクトップ CSS: %s\n", strings.Join(existing.Desktop.CSS, ", "))
+	}
+	if len(existing.Mobile.JS) > 0 {
+		fmt.Printf("    モバイル JS: %s\n", strings.Join(existing.Mobile.JS, ", "))
+	}
+	if len(existing.Mobile.CSS) > 0 {
+		fmt.Printf("    モバイル CSS: %s\n", strings.Join(existing.Mobile.CSS, ", "))
+	}
+	fmt.Println("    （--mode merge を指定すると既存のカスタマイズを残したままデプロイできます）")
+
+	fmt.Println()
+
+	var confirm bool
+	err = ui.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("これらのカスタマイズは上書きされます。続行しますか?").
+				Affirmative("はい").
+				Negative("いいえ").
+				Value(&confirm),
+		),
+	).Run()
+	if err != nil {
+		return false, fmt.Errorf("キャンセルされました")
+	}
+	if !confirm {
+		fmt.Println("デプロイをキャンセルしました。")
+		return false, nil
+	}
+	fmt.Println()
+	return true, nil
+}
+
+// deployCustomize はファイルをアップロードしてカスタマイズ設定を更新し、プレビューのみでなければ本番に反映する
+// 変更前の設定のバックアップを返す（バックアップ作成後に失敗した場合もエラーと一緒に返す）
+func deployCustomize(ctx context.Context, projectDir string, client *kintone.Client, cfg *config.Config, files customizeUpload, opts deployOptions) (*snapshot.Snapshot, error) {
+	upload := func(ctx context.Context, libs config.LibrarySet) (*kintone.CustomizeFiles, error) {
+		jsKey, err := client.UploadFile(ctx, files.JSPath)
+		if err != nil {
+			return nil, fmt.Errorf("JSファイルアップロードエラー: %w", err)
+		}
+		result := &kintone.CustomizeFiles{JSFileKey: jsKey}
+
+		if files.CSSPath != "" {
+			cssKey, err := client.UploadFile(ctx, files.CSSPath)
+			if err != nil {
+				return nil, fmt.Errorf("CSSファイルアップロードエラー: %w", err)
+			}
+			result.CSSFileKey = cssKey
+		}
+
+		if result.JSBefore, result.JSAfter, err = uploadLibraries(ctx, projectDir, client, libs.JS); err != nil {
+			return nil, err
+		}
+		if result.CSSBefore, result.CSSAfter, err = uploadLibraries(ctx, projectDir, client, libs.CSS); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	var libs config.LibrariesConfig
+	if cfg.Libraries != nil {
+		libs = *cfg.Libraries
+	}
+
+	stage := stageNone
+	var backup *snapshot.Snapshot
+	err := ui.SpinnerContext(ctx, opts.Title, func(ctx context.Context) error {
+		var desktopFiles *kintone.CustomizeFiles
+		var mobileFiles *kintone.CustomizeFiles
+		var err error
+
+		// 変更前のカスタマイズ設定をバックアップ（kcdev rollback で復元できる）
+		if backup, err = snapshot.Create(ctx, projectDir, client, cfg, opts.Command); err != nil {
+			return fmt.Errorf("バックアップの作成に失敗しました: %w", err)
+		}
+
+		// デスクトップ用ファイルをアップロード
+		if cfg.Targets.Desktop {
+			if desktopFiles, err = upload(ctx, libs.Desktop); err != nil {
+				return err
+			}
+		}
+
+		// モバイル用ファイルをアップロード
+		if cfg.Targets.Mobile {
+			if mobileFiles, err = upload(ctx, libs.Mobile); err != nil {
+				return err
+			}
+		}
+		stage = stageUploaded
+
+		// カスタマイズ設定を更新
+		scope := kintone.CustomizeScope(cfg.Scope)
+		if scope == "" {
+			scope = kintone.ScopeAll
+		}
+		if opts.Mode == config.DeployModeMerge {
+			err = client.UpdateCustomizeMerge(ctx, cfg.Kintone.AppID, desktopFiles, mobileFiles, scope, managedFileNames(cfg), mergePosition(cfg))
+		} else {
+			err = client.UpdateCustomize(ctx, cfg.Kintone.AppID, desktopFiles, mobileFiles, scope)
+		}
+		if err != nil {
+			return fmt.Errorf("カスタマイズ設定エラー: %w", err)
+		}
+		stage = stagePreviewUpdated
+
+		// アプリをデプロイ（プレビューのみの場合はスキップ）
+		if !opts.PreviewOnly {
+			if err := client.DeployApp(ctx, cfg.Kintone.AppID); err != nil {
+				return fmt.Errorf("デプロイ開始エラー: %w", err)
+			}
+			stage = stageDeployStarted
+
+			if err := client.WaitForDeploy(ctx, cfg.Kintone.AppID); err != nil {
+				return fmt.Errorf("デプロイ待機エラー: %w", err)
+			}
+			stage = stageDeployed
+		}
+		return nil
+	})
+
+	if err != nil {
+		return backup, reportInterrupted(err, stage)
+	}
+
+	if err := snapshot.Prune(projectDir, cfg.GetBackupRetention()); err != nil {
+		ui.Warn(fmt.Sprintf("古いバックアップの削除に失敗しました: %v", err))
+	}
+
+	ui.Info(fmt.Sprintf("変更前の設定をバックアップしました: %s（kcdev rollback で復元できます）", backup.ID))
+	return backup, nil
+}
+
+// uploadLibraries はライブラリをカスタマイズ設定のエントリに変換する
+// ローカルファイルはアップロードし、kcdev のファイルより前と後ろに分けて返す
+func uploadLibraries(ctx context.Context, projectDir string, client *kintone.Client, libs []config.Library) (before, after []kintone.FileCustomization, err error) {
+	for _, lib := range libs {
+		var entry kintone.FileCustomization
+		switch {
+		case lib.URL != "":
+			entry = kintone.FileCustomization{Type: "URL", URL: lib.URL}
+		case lib.File != "":
+			fileKey, err := client.UploadFile(ctx, resolvePath(projectDir, lib.File))
+			if err != nil {
+				return nil, nil, fmt.Errorf("ライブラリのアップロードエラー (%s): %w", lib.File, err)
+			}
+			entry = kintone.FileCustomization{Type: "FILE", File: &kintone.File{FileKey: fileKey}}
+		default:
+			continue
+		}
+
+		if lib.IsAfter() {
+			after = append(after, entry)
+		} else {
+			before = append(before, entry)
+		}
+	}
+	return before, after, nil
+}
+
+func mergePosition(cfg *config.Config) kintone.MergePosition {
+	if cfg.Deploy == nil || cfg.Deploy.Position == nil {
+		return kintone.MergePosition{}
+	}
+	return kintone.MergePosition{
+		After:  cfg.Deploy.Position.After,
+		Before: cfg.Deploy.Position.Before,
+	}
+}
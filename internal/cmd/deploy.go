@@ -4,11 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/kintone/kcdev/internal/config"
-	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/snapshot"
 	"github.com/kintone/kcdev/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +15,7 @@ import (
 var forceOverwrite bool
 var previewOnlyDeploy bool
 var skipVersionDeploy bool
+var deployModeDeploy string
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
@@ -28,6 +28,7 @@ func init() {
 	deployCmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "既存カスタマイズを確認せず上書き")
 	deployCmd.Flags().BoolVarP(&previewOnlyDeploy, "preview", "p", false, "プレビュー環境のみにデプロイ（本番反映しない）")
 	deployCmd.Flags().BoolVar(&skipVersionDeploy, "skip-version", false, "バージョン確認をスキップ")
+	deployCmd.Flags().StringVar(&deployModeDeploy, "mode", "", "カスタマイズ設定の更新方法（overwrite / merge）")
 }
 
 func runDeploy(cmd *cobra.Command, args []string) error {
@@ -41,20 +42,18 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("設定ファイルが見つかりません。kcdev init を実行してください: %w", err)
 	}
 
-	envCfg, _ := config.LoadEnv(projectDir)
-
-	username := cfg.Kintone.Auth.Username
-	password := cfg.Kintone.Auth.Password
-
-	if envCfg != nil && envCfg.HasAuth() {
-		username = envCfg.Username
-		password = envCfg.Password
+	mode, err := resolveDeployMode(cfg, deployModeDeploy)
+	if err != nil {
+		return err
 	}
 
-	if username == "" || password == "" {
-		return fmt.Errorf("認証情報が見つかりません。.env または .kcdev/config.json に設定してください")
+	client, err := newClient(projectDir, cfg)
+	if err != nil {
+		return err
 	}
 
+	ctx := cmd.Context()
+
 	// 設定から出力ファイル名を取得
 	outputName := cfg.GetOutputName()
 
@@ -101,59 +100,21 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("ビルド成果物が見つかりません")
 	}
 
-	client := kintone.NewClient(cfg.Kintone.Domain, username, password)
-
 	// 既存カスタマイズの確認
 	if !forceOverwrite {
-		kcdevFiles := []string{outputName + ".js", outputName + ".css", "kintone-dev-loader.js"}
-		existing, err := client.GetExistingCustomizations(cfg.Kintone.AppID, kcdevFiles)
+		ok, err := confirmOverwrite(ctx, client, cfg, mode)
 		if err != nil {
-			ui.Warn(fmt.Sprintf("既存カスタマイズの確認をスキップ: %v", err))
-		} else if existing.HasExisting() {
-			fmt.Println()
-			ui.Warn("既存のカスタマイズが検出されました:")
-
-			// 詳細を表示
-			if len(existing.Desktop.JS) > 0 {
-				fmt.Printf("    デスクトップ JS: %s\n", strings.Join(existing.Desktop.JS, ", "))
-			}
-			if len(existing.Desktop.CSS) > 0 {
-				fmt.Printf("    デスクトップ CSS: %s\n", strings.Join(existing.Desktop.CSS, ", "))
-			}
-			if len(existing.Mobile.JS) > 0 {
-				fmt.Printf("    モバイル JS: %s\n", strings.Join(existing.Mobile.JS, ", "))
-			}
-			if len(existing.Mobile.CSS) > 0 {
-				fmt.Printf("    モバイル CSS: %s\n", strings.Join(existing.Mobile.CSS, ", "))
-			}
-
-			fmt.Println()
-
-			var confirm bool
-			err := ui.NewForm(
-				huh.NewGroup(
-					huh.NewConfirm().
-						Title("これらのカスタマイズは上書きされます。続行しますか?").
-						Affirmative("はい").
-						Negative("いいえ").
-						Value(&confirm),
-				),
-			).Run()
-			if err != nil {
-				return fmt.Errorf("キャンセルされました")
-			}
-			if !confirm {
-				fmt.Println("デプロイをキャンセルしました。")
-				return nil
-			}
-			fmt.Println()
+			return err
+		}
+		if !ok {
+			return nil
 		}
 	}
 
+	files := customizeUpload{JSPath: jsPath}
 	cssPath := filepath.Join(projectDir, "dist", outputName+".css")
-	hasCss := false
 	if _, err := os.Stat(cssPath); err == nil {
-		hasCss = true
+		files.CSSPath = cssPath
 	}
 
 	// スピナーでデプロイ処理
@@ -162,82 +123,28 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		spinnerTitle = "プレビュー環境にデプロイ中..."
 	}
 
-	var deployErr error
-	ui.Spinner(spinnerTitle, func() {
-		var desktopFiles *kintone.CustomizeFiles
-		var mobileFiles *kintone.CustomizeFiles
-
-		// デスクトップ用ファイルをアップロード
-		if cfg.Targets.Desktop {
-			jsKey, err := client.UploadFile(jsPath)
-			if err != nil {
-				deployErr = fmt.Errorf("JSファイルアップロードエラー: %w", err)
-				return
-			}
-			desktopFiles = &kintone.CustomizeFiles{JSFileKey: jsKey}
-
-			if hasCss {
-				cssKey, err := client.UploadFile(cssPath)
-				if err != nil {
-					deployErr = fmt.Errorf("CSSファイルアップロードエラー: %w", err)
-					return
-				}
-				desktopFiles.CSSFileKey = cssKey
-			}
-		}
-
-		// モバイル用ファイルをアップロード
-		if cfg.Targets.Mobile {
-			jsKey, err := client.UploadFile(jsPath)
-			if err != nil {
-				deployErr = fmt.Errorf("JSファイルアップロードエラー: %w", err)
-				return
-			}
-			mobileFiles = &kintone.CustomizeFiles{JSFileKey: jsKey}
-
-			if hasCss {
-				cssKey, err := client.UploadFile(cssPath)
-				if err != nil {
-					deployErr = fmt.Errorf("CSSファイルアップロードエラー: %w", err)
-					return
-				}
-				mobileFiles.CSSFileKey = cssKey
-			}
-		}
-
-		// カスタマイズ設定を更新
-		scope := kintone.CustomizeScope(cfg.Scope)
-		if scope == "" {
-			scope = kintone.ScopeAll
-		}
-		if err := client.UpdateCustomize(cfg.Kintone.AppID, desktopFiles, mobileFiles, scope); err != nil {
-			deployErr = fmt.Errorf("カスタマイズ設定エラー: %w", err)
-			return
-		}
-
-		// アプリをデプロイ（プレビューのみの場合はスキップ）
-		if !previewOnlyDeploy {
-			if err := client.DeployApp(cfg.Kintone.AppID); err != nil {
-				deployErr = fmt.Errorf("デプロイ開始エラー: %w", err)
-				return
-			}
-
-			if err := client.WaitForDeploy(cfg.Kintone.AppID); err != nil {
-				deployErr = fmt.Errorf("デプロイ待機エラー: %w", err)
-				return
-			}
-		}
+	_, err = deployCustomize(ctx, projectDir, client, cfg, files, deployOptions{
+		Command:     "deploy",
+		Title:       spinnerTitle,
+		Mode:        mode,
+		PreviewOnly: previewOnlyDeploy,
 	})
+	if err != nil {
+		return err
+	}
 
-	if deployErr != nil {
-		return deployErr
+	// dev ローダーが対象外のユーザーに適用する本番バンドルとして記録する
+	if !previewOnlyDeploy {
+		if err := snapshot.RecordDeploy(projectDir, cfg.Kintone.AppID, files.JSPath, files.CSSPath); err != nil {
+			ui.Warn(fmt.Sprintf("デプロイ内容の記録に失敗しました: %v", err))
+		}
 	}
 
 	if !previewOnlyDeploy {
-		ui.Success(fmt.Sprintf("完了! https://%s/k/%d/", cfg.Kintone.Domain, cfg.Kintone.AppID))
+		ui.Success(fmt.Sprintf("完了! %s", cfg.Kintone.AppURL()))
 	} else {
 		ui.Warn("プレビュー環境のみに適用（本番反映はスキップ）")
-		ui.Success(fmt.Sprintf("プレビュー環境に適用しました! https://%s/k/admin/app/flow?app=%d", cfg.Kintone.Domain, cfg.Kintone.AppID))
+		ui.Success(fmt.Sprintf("プレビュー環境に適用しました! %s", cfg.Kintone.AppSettingsURL()))
 	}
 	fmt.Println()
 
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,11 +14,11 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kintone/kcdev/internal/config"
 	"github.com/kintone/kcdev/internal/generator"
 	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/snapshot"
 	"github.com/kintone/kcdev/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +27,11 @@ var skipDeploy bool
 var noBrowser bool
 var forceDevOverwrite bool
 var previewOnlyDev bool
+var deployModeDev string
+var restoreDev bool
+var noRestoreDev bool
+var lanDev bool
+var lanIPDev string
 
 var devCmd = &cobra.Command{
 	Use:   "dev",
@@ -37,6 +45,11 @@ func init() {
 	devCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "ブラウザを自動で開かない")
 	devCmd.Flags().BoolVarP(&forceDevOverwrite, "force", "f", false, "既存カスタマイズを確認せず上書き")
 	devCmd.Flags().BoolVarP(&previewOnlyDev, "preview", "p", false, "プレビュー環境のみにデプロイ（本番反映しない）")
+	devCmd.Flags().StringVar(&deployModeDev, "mode", "", "カスタマイズ設定の更新方法（overwrite / merge）")
+	devCmd.Flags().BoolVar(&restoreDev, "restore", false, "前回の dev で置き換えたカスタマイズ設定を復元して終了")
+	devCmd.Flags().BoolVar(&noRestoreDev, "no-restore", false, "終了時にカスタマイズ設定を復元しない")
+	devCmd.Flags().BoolVar(&lanDev, "lan", false, "開発サーバーを LAN に公開し、モバイル端末から読み込めるようにする")
+	devCmd.Flags().StringVar(&lanIPDev, "lan-ip", "", "--lan で公開する IP アドレス（候補が複数ある場合）")
 }
 
 func runDev(cmd *cobra.Command, args []string) error {
@@ -50,33 +63,121 @@ func runDev(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("設定ファイルが見つかりません。kcdev init を実行してください: %w", err)
 	}
 
-	if !generator.CertsExist(projectDir) {
-		return fmt.Errorf("証明書が見つかりません。kcdev init を実行してください")
+	if restoreDev {
+		return restoreDevSession(projectDir, cfg)
+	}
+
+	// --lan では LAN の IP アドレスで公開し、ローダーもそのオリジンを向ける
+	certHosts := cfg.Dev.GetCertHosts()
+	var lan *lanInfo
+	if lanIPDev != "" {
+		lanDev = true
+	}
+	if lanDev {
+		addr, alternatives, err := selectLANAddr(lanIPDev)
+		if err != nil {
+			return err
+		}
+		// モバイルのカスタマイズ設定は、targets.mobile が無効なら確認してから置き換える
+		mobile := cfg.Targets.Mobile
+		if !mobile {
+			if mobile, err = confirmLANMobile(); err != nil {
+				return err
+			}
+		}
+		lan = &lanInfo{IP: addr.IP, Alternatives: alternatives, Mobile: mobile}
+		certHosts = append(certHosts, addr.IP)
+		for _, a := range alternatives {
+			certHosts = append(certHosts, a.IP)
+		}
+		cfg = lanConfig(cfg, lan.IP, mobile)
+	}
+
+	// バンドルの配信に要求するセッショントークン（kcdev dev の起動ごとに作り直す）
+	var sessionToken string
+	if cfg.Dev.SessionToken {
+		if sessionToken, err = newSessionToken(); err != nil {
+			return err
+		}
+		if lan != nil {
+			lan.Token = sessionToken
+		}
+	}
+
+	if err := ensureManagedFiles(projectDir, cfg); err != nil {
+		return err
+	}
+
+	// 証明書が無い・期限が近い・ホストに対応していない場合はローカル CA で発行し直す
+	if err := ensureDevCerts(projectDir, certHosts); err != nil {
+		return err
+	}
+
+	mode, err := resolveDeployMode(cfg, deployModeDev)
+	if err != nil {
+		return err
 	}
 
 	// 認証情報取得
-	username := cfg.Kintone.Auth.Username
-	password := cfg.Kintone.Auth.Password
+	client, err := newClient(projectDir, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
 
-	envCfg, _ := config.LoadEnv(projectDir)
-	if envCfg != nil && envCfg.HasAuth() {
-		username = envCfg.Username
-		password = envCfg.Password
+	// 前回の dev が異常終了している場合、アプリには前回のローダーが残っている
+	// その時点の設定ではなく、前回のバックアップ（本来の設定）を復元対象として引き継ぐ
+	session, err := snapshot.LoadSession(projectDir)
+	if err != nil {
+		return fmt.Errorf("dev セッションの読み込みエラー: %w", err)
+	}
+	if session != nil {
+		ui.Warn(fmt.Sprintf("前回の dev が正常に終了していません。終了時にバックアップ %s から復元します", session.SnapshotID))
 	}
 
-	if username == "" || password == "" {
-		return fmt.Errorf("認証情報が見つかりません。.env または .kcdev/config.json に設定してください")
+	// 登録済みのローダーが別のオリジンを向いている場合、スキップ指定でも再デプロイする
+	if skipDeploy {
+		if stale, reason := generator.DeployedLoaderStale(projectDir, cfg.Dev.DevOrigin()); stale {
+			ui.Warn(fmt.Sprintf("%s。ローダーを再デプロイします", reason))
+			skipDeploy = false
+		}
 	}
 
 	// デプロイ
 	if !skipDeploy {
-		if err := deployLoader(projectDir, cfg, username, password, forceDevOverwrite, previewOnlyDev); err != nil {
+		backup, err := deployLoader(ctx, projectDir, cfg, client, forceDevOverwrite, previewOnlyDev, mode)
+		// 前回のセッションがプレビューのみでも、今回本番環境にデプロイした場合は本番環境も復元する
+		// デプロイが途中で失敗した場合も、本番環境に反映された可能性があるため同じように記録する
+		if backup != nil {
+			var saveErr error
+			session, saveErr = snapshot.RecordDevDeploy(projectDir, session, backup.ID, previewOnlyDev)
+			if saveErr != nil {
+				ui.Warn(fmt.Sprintf("dev セッションの記録に失敗しました: %v", saveErr))
+			}
+		}
+		if err != nil {
+			if session != nil {
+				ui.Info("kcdev dev --restore で dev 開始前の設定に戻せます")
+			}
 			return err
 		}
 	}
 
 	printDevInfo(cfg)
 
+	if lan != nil {
+		certCtx, stopCert := context.WithCancel(ctx)
+		defer stopCert()
+		certURL, err := startCertServer(certCtx, cfg, lan)
+		if err != nil {
+			ui.Warn(fmt.Sprintf("証明書のページを公開できませんでした: %v", err))
+		} else {
+			lan.CertPageURL = certURL
+		}
+		printLANInfo(cfg, lan)
+	}
+
 	viteConfig := filepath.Join(projectDir, config.ConfigDir, "vite.config.ts")
 	if _, err := os.Stat(filepath.Join(projectDir, "vite.config.ts")); err == nil {
 		viteConfig = filepath.Join(projectDir, "vite.config.ts")
@@ -87,24 +188,40 @@ func runDev(cmd *cobra.Command, args []string) error {
 	viteCmd.Stdout = os.Stdout
 	viteCmd.Stderr = os.Stderr
 	viteCmd.Stdin = os.Stdin
+	viteCmd.Env = append(os.Environ(), "KCDEV_ALLOWED_ORIGINS="+strings.Join(kintoneOrigins(cfg), ","))
+	if lan != nil {
+		viteCmd.Env = append(viteCmd.Env, "KCDEV_DEV_HOST="+lan.IP)
+	}
+	if sessionToken != "" {
+		viteCmd.Env = append(viteCmd.Env, "KCDEV_SESSION_TOKEN="+sessionToken)
+	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	// ブラウザのログを受け取り、Vite に転送先を渡す
+	if cfg.Dev.GetForwardLogs() {
+		logCtx, stopLogs := context.WithCancel(ctx)
+		defer stopLogs()
+		logURL, err := startLogServer(logCtx)
+		if err != nil {
+			ui.Warn(fmt.Sprintf("ブラウザのログ転送を開始できませんでした: %v", err))
+		} else {
+			viteCmd.Env = append(viteCmd.Env, "KCDEV_LOG_URL="+logURL)
+		}
+	}
 
 	if err := viteCmd.Start(); err != nil {
 		return fmt.Errorf("Vite起動エラー: %w", err)
 	}
 
-	// ブラウザを自動で開く（localhost:3000でSSL許可後、kintoneにリダイレクト）
+	// ブラウザを自動で開く（開発サーバーでSSL許可後、kintoneにリダイレクト）
 	if !noBrowser {
 		go func() {
 			time.Sleep(2 * time.Second) // Viteの起動を待つ
-			openBrowser("https://localhost:3000")
+			openBrowser(cfg.Dev.DevOrigin())
 		}()
 	}
 
 	go func() {
-		<-sigChan
+		<-ctx.Done()
 		if viteCmd.Process != nil {
 			viteCmd.Process.Signal(syscall.SIGTERM)
 		}
@@ -115,13 +232,131 @@ func runDev(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() == -1 || exitErr.ProcessState.String() == "signal: terminated" {
-				return nil
+				err = nil
+			}
+		}
+	}
+
+	// Vite が終了したらローダーを外し、dev 開始前の設定に戻す
+	if session != nil && !noRestoreDev {
+		fmt.Println()
+		if restoreErr := restoreDevSession(projectDir, cfg); restoreErr != nil {
+			ui.Error(fmt.Sprintf("カスタマイズ設定の復元に失敗しました: %v", restoreErr))
+			ui.Info("kcdev dev --restore で再試行できます")
+			if err == nil {
+				err = restoreErr
 			}
 		}
 	}
 	return err
 }
 
+// restoreDevSession は dev セッションで記録したバックアップからカスタマイズ設定を復元する
+// Ctrl-C で dev を終了した後も復元できるよう、ルートとは別のコンテキストで実行する
+func restoreDevSession(projectDir string, cfg *config.Config) error {
+	session, err := snapshot.LoadSession(projectDir)
+	if err != nil {
+		return fmt.Errorf("dev セッションの読み込みエラー: %w", err)
+	}
+	if session == nil {
+		ui.Info("復元が必要な dev セッションはありません")
+		return nil
+	}
+
+	target, err := snapshot.Load(projectDir, session.SnapshotID)
+	if err != nil {
+		return fmt.Errorf("バックアップ %s が見つかりません: %w", session.SnapshotID, err)
+	}
+
+	client, err := newClient(projectDir, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// プレビューのみにデプロイしたセッションは本番環境を変更していないため、プレビューだけ戻す
+	title := "dev 開始前のカスタマイズ設定を復元中..."
+	if session.PreviewOnly {
+		title = "dev 開始前のプレビュー環境の設定を復元中..."
+	}
+	if err := restoreSnapshot(ctx, projectDir, client, cfg, target, title, session.PreviewOnly); err != nil {
+		return err
+	}
+
+	if err := snapshot.ClearSession(projectDir); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("dev 開始前の設定に復元しました（バックアップ %s）", target.ID))
+	return nil
+}
+
+// viteConfigMarkers は kcdev dev が Vite に渡す設定を読み込む形式の vite.config.ts に含まれる文字列
+var viteConfigMarkers = []string{"KCDEV_DEV_HOST", "KCDEV_ALLOWED_ORIGINS", "KCDEV_SESSION_TOKEN", "isAllowedLogRequest", "sessionBase", "isBuild"}
+
+// ensureManagedFiles は kcdev が管理する vite.config.ts と index.html が古い形式の場合に生成し直す
+// プロジェクトルートの vite.config.ts を使う場合は Vite 設定を変更しない
+func ensureManagedFiles(projectDir string, cfg *config.Config) error {
+	if _, err := os.Stat(filepath.Join(projectDir, "vite.config.ts")); err != nil {
+		data, err := os.ReadFile(filepath.Join(projectDir, config.ConfigDir, "vite.config.ts"))
+		if err == nil && !containsAll(string(data), viteConfigMarkers) {
+			ui.Info("Vite 設定を最新の形式で生成し直します")
+			if err := generator.GenerateViteConfig(projectDir, detectCurrentFramework(projectDir), detectCurrentLanguage(projectDir)); err != nil {
+				return fmt.Errorf("Vite設定生成エラー: %w", err)
+			}
+		}
+	}
+
+	if cfg.Dev.SessionToken {
+		data, err := os.ReadFile(filepath.Join(projectDir, config.ConfigDir, "index.html"))
+		if err == nil && !strings.Contains(string(data), "kcdevToken") {
+			ui.Info("セッショントークンを渡せるよう、.kcdev/index.html を生成し直します")
+			if err := generator.GenerateIndexHTML(projectDir, cfg.Kintone.AppURL()); err != nil {
+				return fmt.Errorf("index.html 生成エラー: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func containsAll(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// newSessionToken は kcdev dev の起動ごとのセッショントークンを生成する
+func newSessionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// withSessionToken は kintone の URL に、ローダーが読み取るセッショントークンのフラグメントを付ける
+func withSessionToken(url, token string) string {
+	if token == "" {
+		return url
+	}
+	return url + "#kcdev-token=" + token
+}
+
+// kintoneOrigins は開発サーバーが CORS を許可する kintone のオリジンを返す
+// モバイル版やゲストスペースのページも同じオリジンで、セキュアアクセス用のドメインも含める
+func kintoneOrigins(cfg *config.Config) []string {
+	domain := cfg.Kintone.Domain
+	origins := []string{"https://" + domain}
+	if secure := kintone.SecureAccessDomain(domain); secure != domain {
+		origins = append(origins, "https://"+secure)
+	}
+	return origins
+}
+
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
 
@@ -137,121 +372,95 @@ func openBrowser(url string) error {
 	return cmd.Start()
 }
 
-func deployLoader(projectDir string, cfg *config.Config, username, password string, force bool, previewOnly bool) error {
-	client := kintone.NewClient(cfg.Kintone.Domain, username, password)
+// deployLoader はローダーをデプロイし、変更前の設定のバックアップを返す
+func deployLoader(ctx context.Context, projectDir string, cfg *config.Config, client *kintone.Client, force bool, previewOnly bool, mode string) (*snapshot.Snapshot, error) {
 	loaderPath := filepath.Join(projectDir, config.ConfigDir, "managed", "kintone-dev-loader.js")
 
 	// 既存カスタマイズの確認
 	if !force {
-		kcdevFiles := []string{"customize.js", "customize.css", "kintone-dev-loader.js"}
-		existing, err := client.GetExistingCustomizations(cfg.Kintone.AppID, kcdevFiles)
+		ok, err := confirmOverwrite(ctx, client, cfg, mode)
 		if err != nil {
-			ui.Warn(fmt.Sprintf("既存カスタマイズの確認をスキップ: %v", err))
-		} else if existing.HasExisting() {
-			fmt.Println()
-			ui.Warn("既存のカスタマイズが検出されました:")
-
-			// 詳細を表示
-			if len(existing.Desktop.JS) > 0 {
-				fmt.Printf("    デスクトップ JS: %s\n", strings.Join(existing.Desktop.JS, ", "))
-			}
-			if len(existing.Desktop.CSS) > 0 {
-				fmt.Printf("    デスクトップ CSS: %s\n", strings.Join(existing.Desktop.CSS, ", "))
-			}
-			if len(existing.Mobile.JS) > 0 {
-				fmt.Printf("    モバイル JS: %s\n", strings.Join(existing.Mobile.JS, ", "))
-			}
-			if len(existing.Mobile.CSS) > 0 {
-				fmt.Printf("    モバイル CSS: %s\n", strings.Join(existing.Mobile.CSS, ", "))
-			}
-
-			fmt.Println()
-
-			var confirm bool
-			err := ui.NewForm(
-				huh.NewGroup(
-					huh.NewConfirm().
-						Title("これらのカスタマイズは上書きされます。続行しますか?").
-						Affirmative("はい").
-						Negative("いいえ").
-						Value(&confirm),
-				),
-			).Run()
-			if err != nil {
-				return fmt.Errorf("キャンセルされました")
-			}
-			if !confirm {
-				fmt.Println("デプロイをキャンセルしました。")
-				return fmt.Errorf("デプロイがキャンセルされました")
-			}
-			fmt.Println()
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("デプロイがキャンセルされました")
 		}
 	}
 
+	// 対象ユーザーと本番バンドルを埋め込んでローダーを再生成
+	opts := loaderOptions(projectDir, cfg)
+	if len(cfg.Dev.Users) > 0 || cfg.Dev.FallbackOnError {
+		opts.Fallback = loadFallbackBundle(projectDir, cfg)
+	}
+	if err := generator.RegenerateLoader(projectDir, opts); err != nil {
+		return nil, fmt.Errorf("ローダー再生成エラー: %w", err)
+	}
+
 	// スピナーでデプロイ処理
 	spinnerTitle := "ローダーをkintoneにデプロイ中..."
 	if previewOnly {
 		spinnerTitle = "ローダーをkintoneプレビュー環境にデプロイ中..."
 	}
 
-	var deployErr error
-	ui.Spinner(spinnerTitle, func() {
-		var desktopFiles *kintone.CustomizeFiles
-		var mobileFiles *kintone.CustomizeFiles
-
-		// デスクトップ用ローダーをアップロード
-		if cfg.Targets.Desktop {
-			fileKey, err := client.UploadFile(loaderPath)
-			if err != nil {
-				deployErr = fmt.Errorf("ローダーアップロードエラー: %w", err)
-				return
-			}
-			desktopFiles = &kintone.CustomizeFiles{JSFileKey: fileKey}
-		}
-
-		// モバイル用ローダーをアップロード
-		if cfg.Targets.Mobile {
-			fileKey, err := client.UploadFile(loaderPath)
-			if err != nil {
-				deployErr = fmt.Errorf("ローダーアップロードエラー: %w", err)
-				return
-			}
-			mobileFiles = &kintone.CustomizeFiles{JSFileKey: fileKey}
-		}
+	backup, err := deployCustomize(ctx, projectDir, client, cfg, customizeUpload{JSPath: loaderPath}, deployOptions{
+		Command:     "dev",
+		Title:       spinnerTitle,
+		Mode:        mode,
+		PreviewOnly: previewOnly,
+	})
+	if err != nil {
+		return backup, err
+	}
+	if err := generator.MarkLoaderDeployed(projectDir); err != nil {
+		ui.Warn(fmt.Sprintf("ローダーのデプロイ記録に失敗しました: %v", err))
+	}
 
-		// カスタマイズ設定を更新
-		scope := kintone.CustomizeScope(cfg.Scope)
-		if scope == "" {
-			scope = kintone.ScopeAll
-		}
-		if err := client.UpdateCustomize(cfg.Kintone.AppID, desktopFiles, mobileFiles, scope); err != nil {
-			deployErr = fmt.Errorf("カスタマイズ設定エラー: %w", err)
-			return
-		}
+	if previewOnly {
+		ui.Warn("プレビュー環境のみに適用（本番反映はスキップ）")
+	}
 
-		// アプリをデプロイ（プレビューのみの場合はスキップ）
-		if !previewOnly {
-			if err := client.DeployApp(cfg.Kintone.AppID); err != nil {
-				deployErr = fmt.Errorf("デプロイ開始エラー: %w", err)
-				return
-			}
+	return backup, nil
+}
 
-			if err := client.WaitForDeploy(cfg.Kintone.AppID); err != nil {
-				deployErr = fmt.Errorf("デプロイ待機エラー: %w", err)
-				return
-			}
-		}
-	})
+// loaderOptions は現在のプロジェクト設定からローダーの生成設定を組み立てる
+func loaderOptions(projectDir string, cfg *config.Config) generator.LoaderOptions {
+	projectName := filepath.Base(projectDir)
+	if meta, err := generator.LoadLoaderMeta(projectDir); err == nil && meta.Project.Name != "" {
+		projectName = meta.Project.Name
+	}
 
-	if deployErr != nil {
-		return deployErr
+	return generator.LoaderOptions{
+		Framework:       detectCurrentFramework(projectDir),
+		Language:        detectCurrentLanguage(projectDir),
+		Output:          cfg.GetOutputName(),
+		ProjectName:     projectName,
+		Domain:          cfg.Kintone.Domain,
+		AppID:           cfg.Kintone.AppID,
+		Users:           cfg.Dev.Users,
+		ForwardLogs:     cfg.Dev.GetForwardLogs(),
+		FallbackOnError: cfg.Dev.FallbackOnError,
+		SchemaVersion:   cfg.Dev.GetLoaderSchemaVersion(),
+		Mode:            cfg.Dev.GetMode(),
+		Entry:           cfg.Dev.Entry,
+		Origin:          cfg.Dev.DevOrigin(),
 	}
+}
 
-	if previewOnly {
-		ui.Warn("プレビュー環境のみに適用（本番反映はスキップ）")
+// loadFallbackBundle は kcdev deploy で最後に本番反映したバンドルを読み込む
+// 記録が無い場合は nil を返し、フォールバックなしでローダーを生成する
+func loadFallbackBundle(projectDir string, cfg *config.Config) *generator.LoaderFallback {
+	state, err := snapshot.LoadDeployState(projectDir)
+	if err != nil || state == nil || state.AppID != cfg.Kintone.AppID {
+		ui.Warn("本番バンドルの記録がないため、フォールバックなしでローダーをデプロイします（kcdev deploy 時に記録されます）")
+		return nil
 	}
 
-	return nil
+	js, css, err := state.ReadBundle(projectDir)
+	if err != nil {
+		ui.Warn(fmt.Sprintf("本番バンドルの読み込みに失敗しました: %v", err))
+		return nil
+	}
+	return &generator.LoaderFallback{JS: js, CSS: css}
 }
 
 func printDevInfo(cfg *config.Config) {
@@ -273,11 +482,20 @@ func printDevInfo(cfg *config.Config) {
 
 	fmt.Println()
 	ui.Info("開発サーバーを起動中...")
-	fmt.Printf("  %s  %s\n", successStyle.Render("➜"), cfg.Dev.Origin)
+	fmt.Printf("  %s  %s\n", successStyle.Render("➜"), cfg.Dev.DevOrigin())
 	fmt.Printf("  %s     %s\n", infoStyle.Render("エントリー:"), cfg.Dev.Entry)
 	fmt.Printf("  %s     %s\n", infoStyle.Render("ターゲット:"), strings.Join(targets, ", "))
+	if cfg.Dev.GetMode() == config.DevModeESM {
+		fmt.Printf("  %s       %s\n", infoStyle.Render("モード:"), "ESM（HMR）")
+	}
+	if len(cfg.Dev.Users) > 0 {
+		fmt.Printf("  %s %s\n", infoStyle.Render("対象ユーザー:"), strings.Join(cfg.Dev.Users, ", "))
+	}
+	if cfg.Dev.SessionToken {
+		fmt.Printf("  %s   %s\n", infoStyle.Render("トークン:"), "有効（"+cfg.Dev.DevOrigin()+" を開いてから kintone に移動してください）")
+	}
 
-	ok, msg, _ := generator.VerifyLoader(".")
+	ok, msg, _ := generator.VerifyLoader(".", cfg.Dev.DevOrigin())
 	if ok {
 		fmt.Printf("  %s       %s\n\n", successStyle.Render("ローダー:"), msg)
 	} else {
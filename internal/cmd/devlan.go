@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/generator"
+	"github.com/kintone/kcdev/internal/ui"
+	"github.com/skip2/go-qrcode"
+)
+
+// lanInfo は --lan で起動した開発サーバーの接続情報
+type lanInfo struct {
+	// IP は開発サーバーを公開する LAN の IP アドレス
+	IP string
+	// CertPageURL は証明書のダウンロードページの URL
+	CertPageURL string
+	// Token はローダーに渡すセッショントークン（使わない場合は空）
+	Token string
+	// Alternatives は選ばなかった LAN の IP アドレス（--lan-ip で切り替えられる）
+	Alternatives []lanAddr
+	// Mobile はローダーを kintone モバイルにも登録するか（false の場合はデスクトップ版の URL を案内する）
+	Mobile bool
+}
+
+// lanAddr は LAN の IP アドレスと、そのネットワークインターフェース
+type lanAddr struct {
+	IP        string
+	Interface string
+}
+
+func (a lanAddr) String() string {
+	return fmt.Sprintf("%s (%s)", a.IP, a.Interface)
+}
+
+// virtualInterfacePrefixes は Docker・WSL・VPN・仮想マシンなど、ほかの端末から届かないことが多いインターフェースの名前
+var virtualInterfacePrefixes = []string{
+	"docker", "br-", "veth", "virbr", "vboxnet", "vmnet", "vethernet", "utun", "tun", "tap", "wg", "tailscale", "zt", "ppp",
+}
+
+func isVirtualInterface(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// appURL は端末で開くアプリの URL を返す（モバイルに登録しない場合はデスクトップ版の URL）
+func (l *lanInfo) appURL(cfg *config.Config) string {
+	if l.Mobile {
+		return cfg.Kintone.MobileAppURL()
+	}
+	return cfg.Kintone.AppURL()
+}
+
+// confirmLANMobile は targets.mobile が無効な場合に、この dev の間だけモバイルにもローダーを登録するかを確認する
+func confirmLANMobile() (bool, error) {
+	var mobile bool
+	err := ui.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("kintone モバイルにもローダーを登録しますか?").
+				Description("config.json の targets.mobile が無効です。登録する場合、dev の間はアプリのモバイルの JavaScript / CSS が置き換わります（終了時に復元します）。\n登録しない場合はデスクトップ版の画面でだけ読み込みます").
+				Affirmative("登録する").
+				Negative("登録しない").
+				Value(&mobile),
+		),
+	).Run()
+	if err != nil {
+		return false, fmt.Errorf("キャンセルされました")
+	}
+	return mobile, nil
+}
+
+// lanAddrs はこのマシンの LAN（プライベートアドレス）の IPv4 アドレスを返す
+// 仮想的なインターフェース（Docker・WSL・VPN など）のアドレスは後ろに並べる
+func lanAddrs() ([]lanAddr, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []lanAddr
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if ip := ipNet.IP.To4(); ip != nil && ip.IsPrivate() {
+				addrs = append(addrs, lanAddr{IP: ip.String(), Interface: iface.Name})
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("LAN の IP アドレスが見つかりません。Wi-Fi などのネットワークに接続しているか確認してください")
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return !isVirtualInterface(addrs[i].Interface) && isVirtualInterface(addrs[j].Interface)
+	})
+	return addrs, nil
+}
+
+// selectLANAddr は開発サーバーを公開する LAN の IP アドレスを選ぶ
+// ip（--lan-ip）を指定した場合はこのマシンのアドレスか確認し、候補が複数ある場合は選択させる
+// 戻り値の 2 つ目は選ばなかった候補
+func selectLANAddr(ip string) (lanAddr, []lanAddr, error) {
+	addrs, err := lanAddrs()
+	if ip != "" {
+		for i, a := range addrs {
+			if a.IP == ip {
+				return a, append(append([]lanAddr{}, addrs[:i]...), addrs[i+1:]...), nil
+			}
+		}
+		if local, ok := localInterfaceAddr(ip); ok {
+			return local, addrs, nil
+		}
+		return lanAddr{}, nil, fmt.Errorf("--lan-ip %s はこのマシンの IP アドレスではありません（候補: %s）", ip, joinLANAddrs(addrs))
+	}
+	if err != nil {
+		return lanAddr{}, nil, err
+	}
+	if len(addrs) == 1 {
+		return addrs[0], nil, nil
+	}
+
+	options := make([]huh.Option[int], len(addrs))
+	for i, a := range addrs {
+		options[i] = huh.NewOption(a.String(), i)
+	}
+	var selected int
+	err = ui.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("開発サーバーを公開する IP アドレスを選択").
+				Description("モバイル端末と同じネットワーク（Wi-Fi など）のアドレスを選んでください。次回から --lan-ip で指定できます").
+				Options(options...).
+				Value(&selected),
+		),
+	).Run()
+	if err != nil {
+		return lanAddr{}, nil, err
+	}
+	return addrs[selected], append(append([]lanAddr{}, addrs[:selected]...), addrs[selected+1:]...), nil
+}
+
+// localInterfaceAddr は ip がこのマシンのネットワークインターフェースのアドレスかを返す
+// プライベートアドレス以外（固定のグローバルアドレスなど）を --lan-ip で指定する場合に使う
+func localInterfaceAddr(ip string) (lanAddr, bool) {
+	target := net.ParseIP(ip)
+	if target == nil {
+		return lanAddr{}, false
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return lanAddr{}, false
+	}
+	for _, iface := range ifaces {
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(target) {
+				return lanAddr{IP: ip, Interface: iface.Name}, true
+			}
+		}
+	}
+	return lanAddr{}, false
+}
+
+func joinLANAddrs(addrs []lanAddr) string {
+	if len(addrs) == 0 {
+		return "なし"
+	}
+	names := make([]string, len(addrs))
+	for i, a := range addrs {
+		names[i] = a.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// lanConfig は開発サーバーを LAN の IP アドレスで公開する設定を返す
+// mobile の場合はモバイルからも読み込めるよう、ローダーをモバイルにも登録する（config.json は変更しない）
+func lanConfig(cfg *config.Config, ip string, mobile bool) *config.Config {
+	lanCfg := *cfg
+	lanCfg.Dev.SetServer(ip, cfg.Dev.GetPort())
+	if mobile {
+		lanCfg.Targets.Mobile = true
+	}
+	return &lanCfg
+}
+
+// certPageTemplate はモバイル端末に開発サーバーの証明書を入れるためのページ
+var certPageTemplate = template.Must(template.New("cert").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>kcdev 開発サーバーの証明書</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 24px; line-height: 1.6; color: #333; }
+a.button { display: block; margin: 16px 0; padding: 12px; border-radius: 6px; background: #3498db; color: #fff; text-align: center; text-decoration: none; }
+code { background: #f0f0f0; padding: 2px 4px; }
+</style>
+</head>
+<body>
+<h1>kcdev 開発サーバー</h1>
+<p>この端末で <code>{{.Origin}}</code> のコードを読み込むには、開発サーバーの証明書を発行した kcdev のローカル CA を信頼する必要があります。一度信頼させれば、証明書を発行し直しても再設定は不要です。</p>
+<a class="button" href="/kcdev-ca.crt">CA 証明書をダウンロード</a>
+<ul>
+<li>iOS: ダウンロード後「設定」→「プロファイルがダウンロードされました」からインストールし、「一般」→「情報」→「証明書信頼設定」で有効にします</li>
+<li>Android: 「設定」→「セキュリティ」→「証明書のインストール」から CA 証明書としてインストールします</li>
+</ul>
+<p>インストールできない場合は、次のリンクを開いてブラウザの警告を許可してください。</p>
+<a class="button" href="{{.Origin}}/">開発サーバーを開く</a>
+{{if .AppURL}}<p>証明書を信頼したら kintone を開きます。</p>
+<a class="button" href="{{.AppURL}}">kintone を開く</a>
+{{else}}<p>証明書を信頼したら、kcdev のターミナルに表示された QR コードを読み取って kintone を開きます。</p>
+{{end}}</body>
+</html>
+`))
+
+// startCertServer は LAN にローカル CA の証明書のダウンロードページを公開し、その URL を返す
+// 証明書を信頼する前に開くページのため、HTTP で配信する
+// 認証の無いページのため、セッショントークンは載せない（トークンはターミナルの QR コードだけで渡す）
+// ctx がキャンセルされるとサーバーを停止する
+func startCertServer(ctx context.Context, cfg *config.Config, lan *lanInfo) (string, error) {
+	caCert, err := generator.LoadCACert()
+	if err != nil {
+		return "", err
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(lan.IP, "0"))
+	if err != nil {
+		return "", err
+	}
+
+	page := struct {
+		Origin string
+		// AppURL は端末で開くアプリの URL（セッショントークンを使う場合は空）
+		AppURL string
+	}{Origin: cfg.Dev.DevOrigin()}
+	if lan.Token == "" {
+		page.AppURL = lan.appURL(cfg)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		certPageTemplate.Execute(w, page)
+	})
+	mux.HandleFunc("/kcdev-ca.crt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+		w.Header().Set("Content-Disposition", `attachment; filename="kcdev-ca.crt"`)
+		w.Write(caCert.Raw)
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	return "http://" + ln.Addr().String() + "/", nil
+}
+
+// printLANInfo は証明書のページと kintone モバイルの URL を QR コード付きで表示する
+func printLANInfo(cfg *config.Config, lan *lanInfo) {
+	infoStyle := lipgloss.NewStyle().Foreground(ui.ColorCyan)
+
+	appURL := withSessionToken(lan.appURL(cfg), lan.Token)
+	if lan.CertPageURL != "" {
+		fmt.Printf("  %s   %s\n", infoStyle.Render("証明書:"), lan.CertPageURL)
+	}
+	if lan.Mobile {
+		fmt.Printf("  %s %s\n", infoStyle.Render("モバイル:"), appURL)
+	} else {
+		fmt.Printf("  %s   %s\n", infoStyle.Render("アプリ:"), appURL)
+	}
+	if len(lan.Alternatives) > 0 {
+		fmt.Printf("  %s   %s\n", infoStyle.Render("ほかの候補:"), joinLANAddrs(lan.Alternatives))
+	}
+
+	qr, err := qrcode.New(appURL, qrcode.Medium)
+	if err != nil {
+		ui.Warn(fmt.Sprintf("QR コードを生成できませんでした: %v", err))
+		return
+	}
+	fmt.Println()
+	fmt.Print(qr.ToSmallString(false))
+	fmt.Println()
+	ui.Info("初回は証明書のページを開き、端末にローカル CA の証明書を入れてから QR コードを読み取ってください")
+	if !lan.Mobile {
+		ui.Info("ローダーはモバイルに登録していないため、端末ではデスクトップ版の画面で確認してください")
+	}
+	if len(lan.Alternatives) > 0 {
+		ui.Info(fmt.Sprintf("端末から %s に接続できない場合は、--lan-ip でほかの候補を指定してください", lan.IP))
+	}
+	fmt.Println()
+}
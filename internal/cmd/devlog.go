@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/kintone/kcdev/internal/ui"
+)
+
+// browserLog はローダーが開発サーバー経由で転送するブラウザのログ
+type browserLog struct {
+	Level string   `json:"level"`
+	Args  []string `json:"args"`
+	URL   string   `json:"url"`
+	Event string   `json:"event"`
+	Stack string   `json:"stack"`
+}
+
+// startLogServer はブラウザのログを受け取るローカルサーバーを起動し、その URL を返す
+// Vite の /__kcdev/log は受け取ったログを KCDEV_LOG_URL（この URL）に転送する
+// ctx がキャンセルされるとサーバーを停止する
+func startLogServer(ctx context.Context) (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+
+	// 複数のリクエストが同時に届いても 1 件ずつ表示する
+	var mu sync.Mutex
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var entry browserLog
+			if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&entry) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+			mu.Lock()
+			defer mu.Unlock()
+			printBrowserLog(&entry)
+		}),
+	}
+
+	go srv.Serve(ln)
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	return "http://" + ln.Addr().String(), nil
+}
+
+// printBrowserLog はページの URL と処理中の kintone イベントを付けてログを表示する
+func printBrowserLog(entry *browserLog) {
+	entry.sanitize()
+	source := entry.URL
+	if u, err := url.Parse(entry.URL); err == nil && u.Path != "" {
+		// ドメインは常に同じなので、パスとフラグメント（#record=1 など）だけを表示する
+		source = u.Path
+		if u.Fragment != "" {
+			source += "#" + u.Fragment
+		}
+	}
+	if entry.Event != "" {
+		source += " (" + entry.Event + ")"
+	}
+
+	msg := strings.Join(entry.Args, " ")
+	if entry.Stack != "" && !strings.Contains(msg, entry.Stack) {
+		msg += "\n" + entry.Stack
+	}
+	ui.BrowserLog(entry.Level, source, msg)
+}
+
+// sanitize はターミナルの表示を書き換えられないよう、ブラウザから届いた文字列の制御文字を取り除く
+func (l *browserLog) sanitize() {
+	l.Level = stripControl(l.Level)
+	l.URL = stripControl(l.URL)
+	l.Event = stripControl(l.Event)
+	l.Stack = stripControl(l.Stack)
+	for i, arg := range l.Args {
+		l.Args[i] = stripControl(arg)
+	}
+}
+
+// stripControl は改行とタブ以外の制御文字（ESC によるエスケープシーケンスや CR など）を取り除く
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+}
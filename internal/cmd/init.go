@@ -12,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kintone/kcdev/internal/config"
 	"github.com/kintone/kcdev/internal/generator"
+	"github.com/kintone/kcdev/internal/kintone"
 	"github.com/kintone/kcdev/internal/prompt"
 	"github.com/kintone/kcdev/internal/ui"
 	"github.com/spf13/cobra"
@@ -20,10 +21,12 @@ import (
 var (
 	flagDomain         string
 	flagAppID          int
+	flagGuestSpaceID   int
 	flagFramework      string
 	flagLanguage       string
 	flagUsername       string
 	flagPassword       string
+	flagAPIToken       string
 	flagCreateDir      bool
 	flagNoCreateDir    bool
 	flagDesktop        bool
@@ -44,10 +47,12 @@ var initCmd = &cobra.Command{
 func init() {
 	initCmd.Flags().StringVarP(&flagDomain, "domain", "d", "", "kintone ドメイン")
 	initCmd.Flags().IntVarP(&flagAppID, "app", "a", 0, "アプリ ID")
+	initCmd.Flags().IntVarP(&flagGuestSpaceID, "guest-space", "g", 0, "ゲストスペース ID（ゲストスペース内のアプリの場合）")
 	initCmd.Flags().StringVarP(&flagFramework, "framework", "f", "", "フレームワーク (react|vue|svelte|vanilla)")
 	initCmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "言語 (typescript|javascript)")
 	initCmd.Flags().StringVarP(&flagUsername, "username", "u", "", "kintone ユーザー名")
 	initCmd.Flags().StringVarP(&flagPassword, "password", "p", "", "kintone パスワード")
+	initCmd.Flags().StringVarP(&flagAPIToken, "api-token", "t", "", "kintone API トークン（カンマ区切りで複数指定可）")
 	initCmd.Flags().BoolVar(&flagCreateDir, "create-dir", false, "プロジェクトディレクトリを作成")
 	initCmd.Flags().BoolVar(&flagNoCreateDir, "no-create-dir", false, "カレントディレクトリに展開")
 	initCmd.Flags().BoolVar(&flagDesktop, "desktop", false, "デスクトップを対象に含める")
@@ -125,26 +130,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 	ui.Success("ローダーを生成しました")
 
-	err = ui.SpinnerWithResult("証明書を生成中...", func() error {
-		return generator.GenerateCerts(projectDir)
-	})
-	if err != nil {
-		return fmt.Errorf("証明書生成エラー: %w", err)
-	}
-	ui.Success("証明書を生成しました")
-
 	cfg := &config.Config{
 		Kintone: config.KintoneConfig{
-			Domain: answers.Domain,
-			AppID:  answers.AppID,
+			Domain:       answers.Domain,
+			AppID:        answers.AppID,
+			GuestSpaceID: answers.GuestSpaceID,
 			Auth: config.AuthConfig{
 				Username: answers.Username,
 				Password: answers.Password,
+				APIToken: answers.APIToken,
 			},
 		},
 		Dev: config.DevConfig{
-			Origin: "https://localhost:3000",
-			Entry:  generator.GetEntryPath(answers.Framework, answers.Language),
+			Entry: generator.GetEntryPath(answers.Framework, answers.Language),
 		},
 		Targets: config.TargetsConfig{
 			Desktop: answers.TargetDesktop,
@@ -153,6 +151,18 @@ func runInit(cmd *cobra.Command, args []string) error {
 		Scope:  string(answers.Scope),
 		Output: answers.Output,
 	}
+	cfg.Dev.SetServer(config.DefaultDevHost, config.DefaultDevPort)
+	// 再初期化する場合は、開発サーバーのホスト・ポートと証明書に追加するホストを引き継ぐ
+	if existing, err := config.Load(projectDir); err == nil {
+		cfg.Dev.SetServer(existing.Dev.GetHost(), existing.Dev.GetPort())
+		cfg.Dev.CertHosts = existing.Dev.CertHosts
+	}
+
+	// 開発サーバーの証明書はユーザーごとのローカル CA で、設定したホストを SAN に含めて発行する
+	if err := ensureDevCerts(projectDir, cfg.Dev.GetCertHosts()); err != nil {
+		return err
+	}
+
 	if err := cfg.Save(projectDir); err != nil {
 		return fmt.Errorf("設定保存エラー: %w", err)
 	}
@@ -203,15 +213,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 		ui.Success("パッケージをインストールしました")
 
-		// TypeScript の場合、型定義を生成
-		if answers.Language == prompt.LanguageTypeScript {
-			if err := generateTypes(projectDir, cfg, answers.Username, answers.Password); err != nil {
-				// 型定義生成の失敗は警告のみ（プロジェクト作成は成功として扱う）
-				fmt.Println()
-				ui.Warn(fmt.Sprintf("型定義の生成をスキップしました: %v", err))
-				infoStyle := lipgloss.NewStyle().Foreground(ui.ColorCyan)
-				fmt.Printf("  後で %s を実行して型定義を生成できます\n", infoStyle.Render("kcdev types"))
-			}
+		// 型定義を生成（JavaScript の場合は JSDoc の @typedef も生成）
+		opts := clientOptions(projectDir, cfg)
+		opts.Auth = kintone.Auth{Username: answers.Username, Password: answers.Password, APIToken: answers.APIToken}
+		if err := generateTypes(cmd.Context(), projectDir, cfg, opts); err != nil {
+			// 型定義生成の失敗は警告のみ（プロジェクト作成は成功として扱う）
+			fmt.Println()
+			ui.Warn(fmt.Sprintf("型定義の生成をスキップしました: %v", err))
+			infoStyle := lipgloss.NewStyle().Foreground(ui.ColorCyan)
+			fmt.Printf("  後で %s を実行して型定義を生成できます\n", infoStyle.Render("kcdev types"))
 		}
 	}
 
@@ -264,6 +274,7 @@ func collectAnswers(projectDir string, projectName string) (*prompt.InitAnswers,
 	}
 
 	// ドメイン・アプリID
+	askedApp := false
 	if flagDomain != "" && flagAppID > 0 {
 		answers.Domain = prompt.CompleteDomain(flagDomain)
 		answers.AppID = flagAppID
@@ -274,6 +285,7 @@ func collectAnswers(projectDir string, projectName string) (*prompt.InitAnswers,
 		answers.Domain = cfg.Kintone.Domain
 		answers.AppID = cfg.Kintone.AppID
 	} else {
+		askedApp = true
 		if flagDomain != "" {
 			answers.Domain = prompt.CompleteDomain(flagDomain)
 		} else {
@@ -295,6 +307,19 @@ func collectAnswers(projectDir string, projectName string) (*prompt.InitAnswers,
 		}
 	}
 
+	// ゲストスペース（アプリを対話で入力した場合は、ゲストスペース内のアプリかも尋ねる）
+	if flagGuestSpaceID > 0 {
+		answers.GuestSpaceID = flagGuestSpaceID
+	} else if cfg, err := config.Load(projectDir); err == nil {
+		answers.GuestSpaceID = cfg.Kintone.GuestSpaceID
+	} else if askedApp {
+		guestSpaceID, err := prompt.AskGuestSpaceID(0)
+		if err != nil {
+			return nil, err
+		}
+		answers.GuestSpaceID = guestSpaceID
+	}
+
 	// フレームワーク・言語
 	if flagFramework != "" && flagLanguage != "" {
 		answers.Framework = prompt.Framework(flagFramework)
@@ -328,7 +353,9 @@ func collectAnswers(projectDir string, projectName string) (*prompt.InitAnswers,
 	}
 
 	// 認証情報
-	if flagUsername != "" && flagPassword != "" {
+	if flagAPIToken != "" {
+		answers.APIToken = config.NormalizeAPIToken(flagAPIToken)
+	} else if flagUsername != "" && flagPassword != "" {
 		answers.Username = flagUsername
 		answers.Password = flagPassword
 	} else {
@@ -336,25 +363,43 @@ func collectAnswers(projectDir string, projectName string) (*prompt.InitAnswers,
 		if envCfg != nil && envCfg.HasAuth() {
 			answers.Username = envCfg.Username
 			answers.Password = envCfg.Password
+			answers.APIToken = envCfg.APIToken
 		} else {
-			if flagUsername != "" {
-				answers.Username = flagUsername
-			} else {
-				username, err := prompt.AskUsername()
+			method := prompt.AuthMethodPassword
+			if flagUsername == "" && flagPassword == "" {
+				m, err := prompt.AskAuthMethod()
 				if err != nil {
 					return nil, err
 				}
-				answers.Username = username
+				method = m
 			}
 
-			if flagPassword != "" {
-				answers.Password = flagPassword
-			} else {
-				password, err := prompt.AskPassword()
+			if method == prompt.AuthMethodAPIToken {
+				token, err := prompt.AskAPIToken()
 				if err != nil {
 					return nil, err
 				}
-				answers.Password = password
+				answers.APIToken = config.NormalizeAPIToken(token)
+			} else {
+				if flagUsername != "" {
+					answers.Username = flagUsername
+				} else {
+					username, err := prompt.AskUsername()
+					if err != nil {
+						return nil, err
+					}
+					answers.Username = username
+				}
+
+				if flagPassword != "" {
+					answers.Password = flagPassword
+				} else {
+					password, err := prompt.AskPassword()
+					if err != nil {
+						return nil, err
+					}
+					answers.Password = password
+				}
 			}
 		}
 	}
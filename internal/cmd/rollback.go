@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/snapshot"
+	"github.com/kintone/kcdev/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var rollbackTo string
+var listRollback bool
+var forceRollback bool
+var previewOnlyRollback bool
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "カスタマイズ設定をデプロイ前の状態に戻す",
+	Long:  `dev / deploy の実行前に保存したバックアップから、JS/CSS の一覧・順序・適用範囲を復元します。`,
+	RunE:  runRollback,
+}
+
+func init() {
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "復元するバックアップの ID（省略時は最新）")
+	rollbackCmd.Flags().BoolVarP(&listRollback, "list", "l", false, "バックアップの一覧を表示")
+	rollbackCmd.Flags().BoolVarP(&forceRollback, "force", "f", false, "確認せずに復元")
+	rollbackCmd.Flags().BoolVarP(&previewOnlyRollback, "preview", "p", false, "プレビュー環境のみに復元（本番反映しない）")
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("設定ファイルが見つかりません。kcdev init を実行してください: %w", err)
+	}
+
+	snapshots, err := snapshot.List(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if listRollback {
+		if len(snapshots) == 0 {
+			ui.Info("バックアップはありません")
+			return nil
+		}
+		for _, s := range snapshots {
+			fmt.Printf("  %s\n", s.Summary())
+		}
+		return nil
+	}
+
+	var target *snapshot.Snapshot
+	if rollbackTo != "" {
+		target, err = snapshot.Load(projectDir, rollbackTo)
+		if err != nil {
+			return fmt.Errorf("バックアップ %s が見つかりません: %w", rollbackTo, err)
+		}
+	} else {
+		if len(snapshots) == 0 {
+			return fmt.Errorf("バックアップがありません。kcdev dev / deploy の実行時に自動で作成されます")
+		}
+		target = snapshots[0]
+	}
+
+	if target.AppID != cfg.Kintone.AppID || target.Domain != cfg.Kintone.Domain {
+		return fmt.Errorf("バックアップ %s は別のアプリ（%s / アプリID %d）のものです", target.ID, target.Domain, target.AppID)
+	}
+
+	client, err := newClient(projectDir, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	ui.Info("復元するバックアップ:")
+	fmt.Printf("    %s\n", target.Summary())
+	fmt.Println()
+
+	if !forceRollback {
+		var confirm bool
+		err := ui.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("現在のカスタマイズ設定はこのバックアップの内容で置き換えられます。続行しますか?").
+					Affirmative("はい").
+					Negative("いいえ").
+					Value(&confirm),
+			),
+		).Run()
+		if err != nil {
+			return fmt.Errorf("キャンセルされました")
+		}
+		if !confirm {
+			fmt.Println("復元をキャンセルしました。")
+			return nil
+		}
+		fmt.Println()
+	}
+
+	spinnerTitle := "カスタマイズ設定を復元中..."
+	if previewOnlyRollback {
+		spinnerTitle = "プレビュー環境のカスタマイズ設定を復元中..."
+	}
+
+	ctx := cmd.Context()
+
+	// 復元自体も取り消せるよう、現在の設定をバックアップしておく
+	var current *snapshot.Snapshot
+	err = ui.SpinnerContext(ctx, "現在の設定をバックアップ中...", func(ctx context.Context) error {
+		var err error
+		current, err = snapshot.Create(ctx, projectDir, client, cfg, "rollback")
+		return err
+	})
+	if err != nil {
+		return reportInterrupted(fmt.Errorf("バックアップの作成に失敗しました: %w", err), stageNone)
+	}
+
+	if err := restoreSnapshot(ctx, projectDir, client, cfg, target, spinnerTitle, previewOnlyRollback); err != nil {
+		return err
+	}
+
+	// 復元が終わるまでは対象のバックアップを消さないよう、古いものの削除は最後に行う
+	if err := snapshot.Prune(projectDir, cfg.GetBackupRetention()); err != nil {
+		ui.Warn(fmt.Sprintf("古いバックアップの削除に失敗しました: %v", err))
+	}
+
+	ui.Info(fmt.Sprintf("復元前の設定をバックアップしました: %s", current.ID))
+	if previewOnlyRollback {
+		ui.Warn("プレビュー環境のみに適用（本番反映はスキップ）")
+		ui.Success(fmt.Sprintf("プレビュー環境に復元しました! %s", cfg.Kintone.AppSettingsURL()))
+	} else {
+		ui.Success(fmt.Sprintf("%s の状態に復元しました! %s", target.ID, cfg.Kintone.AppURL()))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// restoreSnapshot はバックアップの内容でカスタマイズ設定を置き換え、previewOnly でなければ本番に反映する
+func restoreSnapshot(ctx context.Context, projectDir string, client *kintone.Client, cfg *config.Config, target *snapshot.Snapshot, title string, previewOnly bool) error {
+	stage := stageNone
+	err := ui.SpinnerContext(ctx, title, func(ctx context.Context) error {
+		if err := target.Restore(ctx, projectDir, client); err != nil {
+			return fmt.Errorf("復元エラー: %w", err)
+		}
+		stage = stagePreviewUpdated
+
+		if !previewOnly {
+			if err := client.DeployApp(ctx, cfg.Kintone.AppID); err != nil {
+				return fmt.Errorf("デプロイ開始エラー: %w", err)
+			}
+			stage = stageDeployStarted
+
+			if err := client.WaitForDeploy(ctx, cfg.Kintone.AppID); err != nil {
+				return fmt.Errorf("デプロイ待機エラー: %w", err)
+			}
+			stage = stageDeployed
+		}
+		return nil
+	})
+	if err != nil {
+		return reportInterrupted(err, stage)
+	}
+	return nil
+}
@@ -1,6 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -15,7 +22,20 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	// Ctrl-C / SIGTERM でルートコンテキストをキャンセルし、各コマンドを安全に中断させる
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// 2 回目のシグナルでは通常どおり即座に終了させる
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if hint := kintone.ErrorHint(err); hint != "" {
+		ui.Info("ヒント: " + hint)
+	}
+	return err
 }
 
 func init() {
@@ -24,4 +44,6 @@ func init() {
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(typesCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(certCmd)
 }
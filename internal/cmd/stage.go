@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kintone/kcdev/internal/ui"
+)
+
+// deployStage はデプロイ処理がどこまで進んだかを表す
+// 中断時にアプリがどの状態で残ったかを利用者に伝えるために使う
+type deployStage int
+
+const (
+	stageNone deployStage = iota
+	stageUploaded
+	stagePreviewUpdated
+	stageDeployStarted
+	stageDeployed
+)
+
+// describe はその段階で中断した場合のアプリの状態を返す
+func (s deployStage) describe() string {
+	switch s {
+	case stageUploaded:
+		return "ファイルはアップロード済みですが、アプリの設定は変更されていません"
+	case stagePreviewUpdated:
+		return "プレビュー環境のカスタマイズ設定は変更済みです（本番環境には未反映）。アプリ設定画面から「アプリを更新」または「変更を中止」してください"
+	case stageDeployStarted:
+		return "本番環境への反映は開始済みです。完了までしばらくかかる場合があるため、アプリ設定画面で状態を確認してください"
+	case stageDeployed:
+		return "本番環境への反映は完了しています"
+	default:
+		return "アプリの設定は変更されていません"
+	}
+}
+
+// reportInterrupted は err がキャンセルによるものであれば、アプリの状態を表示して中断エラーを返す
+// キャンセル以外のエラーはそのまま返す
+func reportInterrupted(err error, stage deployStage) error {
+	if !errors.Is(err, context.Canceled) {
+		return err
+	}
+	fmt.Println()
+	ui.Warn("デプロイを中断しました: " + stage.describe())
+	return fmt.Errorf("中断されました")
+}
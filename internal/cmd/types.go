@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/generator"
+	"github.com/kintone/kcdev/internal/kintone"
+	"github.com/kintone/kcdev/internal/prompt"
+	"github.com/kintone/kcdev/internal/typegen"
 	"github.com/kintone/kcdev/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -14,8 +18,10 @@ import (
 var typesCmd = &cobra.Command{
 	Use:   "types",
 	Short: "kintone フィールド型定義を生成",
-	Long:  `@kintone/dts-gen を使用して、kintone アプリのフィールド型定義を生成します。`,
-	RunE:  runTypes,
+	Long: `kintone アプリのフォームの設定から、フィールドの型定義を生成します。
+ラジオボタン・ドロップダウンなどの選択肢は、設定どおりの文字列の型になります。
+JavaScript プロジェクトでは JSDoc の @typedef と jsconfig.json も生成します。`,
+	RunE: runTypes,
 }
 
 func runTypes(cmd *cobra.Command, args []string) error {
@@ -30,50 +36,165 @@ func runTypes(cmd *cobra.Command, args []string) error {
 	}
 
 	// 認証情報取得
-	username := cfg.Kintone.Auth.Username
-	password := cfg.Kintone.Auth.Password
-
-	envCfg, _ := config.LoadEnv(projectDir)
-	if envCfg != nil && envCfg.HasAuth() {
-		username = envCfg.Username
-		password = envCfg.Password
-	}
-
-	if username == "" || password == "" {
-		return fmt.Errorf("認証情報が見つかりません。.env または .kcdev/config.json に設定してください")
+	auth, err := resolveAuth(projectDir, cfg)
+	if err != nil {
+		return err
 	}
 
-	return generateTypes(projectDir, cfg, username, password)
+	opts := clientOptions(projectDir, cfg)
+	opts.Auth = auth
+	return generateTypes(cmd.Context(), projectDir, cfg, opts)
 }
 
-func generateTypes(projectDir string, cfg *config.Config, username, password string) error {
+func generateTypes(ctx context.Context, projectDir string, cfg *config.Config, opts kintone.ClientOptions) error {
 	fmt.Println()
-	ui.Info("型定義を生成中...")
 
-	// 出力ディレクトリを作成
-	typesDir := filepath.Join(projectDir, "src", "types")
-	if err := os.MkdirAll(typesDir, 0755); err != nil {
-		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	client, err := kintone.NewClient(opts)
+	if err != nil {
+		return err
+	}
+
+	var app *typegen.App
+	var viewsErr, processErr error
+	err = ui.SpinnerContext(ctx, "フォームの設定を取得中...", func(ctx context.Context) error {
+		var err error
+		if app, err = fetchForm(ctx, client, cfg.Kintone.AppID); err != nil {
+			return err
+		}
+		// 一覧・プロセス管理は取得できなくても、それ以外の型と定数は生成する
+		app.Views, viewsErr = client.GetViews(ctx, app.ID)
+		app.Process, processErr = client.GetProcessManagement(ctx, app.ID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if viewsErr != nil {
+		ui.Warn(fmt.Sprintf("一覧の設定を取得できなかったため、一覧の定数を省略します: %v", viewsErr))
+	}
+	if processErr != nil {
+		ui.Warn(fmt.Sprintf("プロセス管理の設定を取得できなかったため、ステータスの型と定数を省略します: %v", processErr))
 	}
 
-	// dts-gen を実行
-	dtsGenCmd := exec.Command("npx", "@kintone/dts-gen",
-		"--base-url", fmt.Sprintf("https://%s", cfg.Kintone.Domain),
-		"--app-id", fmt.Sprintf("%d", cfg.Kintone.AppID),
-		"--username", username,
-		"--password", password,
-		"--output", filepath.Join(typesDir, "kintone.d.ts"),
-	)
-	dtsGenCmd.Dir = projectDir
-	dtsGenCmd.Stdout = os.Stdout
-	dtsGenCmd.Stderr = os.Stderr
+	// ルックアップ・関連レコード一覧の参照先と、config.json の types.relatedApps の型も生成する
+	refs := app.RelatedAppRefs()
+	for _, id := range cfg.GetRelatedApps() {
+		if id != app.ID && !containsAppRef(refs, id) {
+			refs = append(refs, typegen.AppRef{ID: id, Refs: []string{"config.json の types.relatedApps"}})
+		}
+	}
+	if len(refs) > 0 {
+		var skipped []string
+		err := ui.SpinnerContext(ctx, fmt.Sprintf("関連アプリ（%d 件）の設定を取得中...", len(refs)), func(ctx context.Context) error {
+			for _, ref := range refs {
+				related, err := fetchForm(ctx, client, ref.ID)
+				if err != nil {
+					// 中断された場合は残りのアプリを取得せずに終了する
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					// 読み込めないアプリは省略し、ほかのアプリの型は生成する
+					skipped = append(skipped, fmt.Sprintf("アプリ %d: %v", ref.ID, err))
+					continue
+				}
+				related.Refs = ref.Refs
+				app.Related = append(app.Related, related)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, msg := range skipped {
+			ui.Warn(fmt.Sprintf("関連アプリの型を省略しました（%s）", msg))
+		}
+		if len(skipped) > 0 && opts.Auth.UsesAPIToken() {
+			ui.Info("API トークンで認証する場合は、関連アプリの API トークンもカンマ区切りで追加してください")
+		}
+	}
 
-	if err := dtsGenCmd.Run(); err != nil {
-		return fmt.Errorf("dts-gen 実行エラー: %w", err)
+	// JavaScript の場合は JSDoc の @typedef も生成し、jsconfig.json から kintone.d.ts を読み込む
+	// フィールドコードなどの定数は src/generated/app.ts（JavaScript の場合は app.js）に生成する
+	files := []generatedFile{{Path: "src/types/kintone.d.ts", Content: typegen.TypeScript(app)}}
+	if detectCurrentLanguage(projectDir) == prompt.LanguageJavaScript {
+		files = append(files,
+			generatedFile{Path: "src/types/fields.js", Content: typegen.JSDoc(app)},
+			generatedFile{Path: "src/generated/app.js", Content: typegen.Constants(app, false)},
+		)
+		if err := ensureJSConfig(projectDir); err != nil {
+			return err
+		}
+	} else {
+		files = append(files, generatedFile{Path: "src/generated/app.ts", Content: typegen.Constants(app, true)})
+	}
+	if err := writeGeneratedFiles(projectDir, files); err != nil {
+		return err
 	}
 
-	fmt.Println()
-	ui.Success("型定義を生成しました: src/types/kintone.d.ts")
+	msg := fmt.Sprintf("型定義を生成しました（%d フィールド）", len(app.FormFields()))
+	if len(app.Related) > 0 {
+		msg = fmt.Sprintf("型定義を生成しました（%d フィールド、関連アプリ %d 件）", len(app.FormFields()), len(app.Related))
+	}
+	ui.Success(msg)
+	for _, f := range files {
+		fmt.Printf("  %s\n", f.Path)
+	}
 	fmt.Println()
 	return nil
 }
+
+// generatedFile は kcdev types が書き出すファイル（Path はプロジェクトルートからの相対パス）
+type generatedFile struct {
+	Path    string
+	Content string
+}
+
+func writeGeneratedFiles(projectDir string, files []generatedFile) error {
+	for _, f := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(f.Content), 0644); err != nil {
+			return fmt.Errorf("%s の書き込みエラー: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// ensureJSConfig は JavaScript プロジェクトに jsconfig.json が無い場合に生成し、
+// kintone の JavaScript API の型（@kintone/dts-gen）が無い場合はインストールを促す
+func ensureJSConfig(projectDir string) error {
+	if _, err := os.Stat(filepath.Join(projectDir, "jsconfig.json")); os.IsNotExist(err) {
+		if err := generator.GenerateJSConfig(projectDir, detectCurrentFramework(projectDir)); err != nil {
+			return fmt.Errorf("jsconfig.json 生成エラー: %w", err)
+		}
+		ui.Info("jsconfig.json を生成しました（checkJs でフィールドの型を検査します）")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "node_modules", "@kintone", "dts-gen")); os.IsNotExist(err) {
+		ui.Warn("kintone の JavaScript API の型がありません。npm install -D @kintone/dts-gen を実行してください")
+	}
+	return nil
+}
+
+// fetchForm はアプリのフィールドの設定とレイアウトを取得する
+func fetchForm(ctx context.Context, client *kintone.Client, appID int) (*typegen.App, error) {
+	fields, err := client.GetFormFields(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := client.GetFormLayout(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return &typegen.App{ID: appID, Fields: fields, Layout: layout}, nil
+}
+
+func containsAppRef(refs []typegen.AppRef, id int) bool {
+	for _, ref := range refs {
+		if ref.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -12,11 +17,15 @@ const (
 )
 
 type Config struct {
-	Kintone KintoneConfig `json:"kintone"`
-	Dev     DevConfig     `json:"dev"`
-	Targets TargetsConfig `json:"targets"`
-	Scope   string        `json:"scope"`
-	Output  string        `json:"output,omitempty"`
+	Kintone   KintoneConfig    `json:"kintone"`
+	Dev       DevConfig        `json:"dev"`
+	Targets   TargetsConfig    `json:"targets"`
+	Scope     string           `json:"scope"`
+	Output    string           `json:"output,omitempty"`
+	Deploy    *DeployConfig    `json:"deploy,omitempty"`
+	Libraries *LibrariesConfig `json:"libraries,omitempty"`
+	Backup    *BackupConfig    `json:"backup,omitempty"`
+	Types     *TypesConfig     `json:"types,omitempty"`
 }
 
 type TargetsConfig struct {
@@ -32,26 +41,347 @@ const (
 )
 
 type KintoneConfig struct {
-	Domain string     `json:"domain"`
-	AppID  int        `json:"appId"`
-	Auth   AuthConfig `json:"auth,omitempty"`
+	Domain       string            `json:"domain"`
+	AppID        int               `json:"appId"`
+	GuestSpaceID int               `json:"guestSpaceId,omitempty"`
+	Auth         AuthConfig        `json:"auth,omitempty"`
+	BasicAuth    *BasicAuthConfig  `json:"basicAuth,omitempty"`
+	ClientCert   *ClientCertConfig `json:"clientCert,omitempty"`
+	CAFile       string            `json:"caFile,omitempty"`
+	Proxy        *ProxyConfig      `json:"proxy,omitempty"`
+	Retry        *RetryConfig      `json:"retry,omitempty"`
+}
+
+// AppURL はアプリのレコード一覧画面の URL を返す
+func (k KintoneConfig) AppURL() string {
+	if k.GuestSpaceID > 0 {
+		return fmt.Sprintf("https://%s/k/guest/%d/%d/", k.Domain, k.GuestSpaceID, k.AppID)
+	}
+	return fmt.Sprintf("https://%s/k/%d/", k.Domain, k.AppID)
+}
+
+// MobileAppURL はモバイル版のアプリのレコード一覧画面の URL を返す
+func (k KintoneConfig) MobileAppURL() string {
+	if k.GuestSpaceID > 0 {
+		return fmt.Sprintf("https://%s/k/guest/%d/m/%d/", k.Domain, k.GuestSpaceID, k.AppID)
+	}
+	return fmt.Sprintf("https://%s/k/m/%d/", k.Domain, k.AppID)
+}
+
+// AppSettingsURL はアプリ設定画面の URL を返す
+func (k KintoneConfig) AppSettingsURL() string {
+	if k.GuestSpaceID > 0 {
+		return fmt.Sprintf("https://%s/k/guest/%d/admin/app/flow?app=%d", k.Domain, k.GuestSpaceID, k.AppID)
+	}
+	return fmt.Sprintf("https://%s/k/admin/app/flow?app=%d", k.Domain, k.AppID)
+}
+
+// BasicAuthConfig は kintone の前段にある Basic 認証の設定
+type BasicAuthConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+// ClientCertConfig はセキュアアクセス用のクライアント証明書の設定
+// File に PKCS#12 (.pfx/.p12) を指定した場合は KeyFile 不要
+type ClientCertConfig struct {
+	File     string `json:"file"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ProxyConfig は HTTP(S) プロキシの設定
+type ProxyConfig struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// RetryConfig は kintone API の一時的なエラーに対するリトライ設定
+// 未設定の場合は既定値（最大 3 回、500ms から指数的に待機）を使う
+type RetryConfig struct {
+	// MaxRetries は最大リトライ回数（0 でリトライしない）
+	MaxRetries  int `json:"maxRetries"`
+	BaseDelayMs int `json:"baseDelayMs,omitempty"`
+	MaxDelayMs  int `json:"maxDelayMs,omitempty"`
 }
 
 type AuthConfig struct {
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
+	APIToken string `json:"apiToken,omitempty"`
+}
+
+// HasPassword はパスワード認証の情報が揃っているかチェック
+func (a AuthConfig) HasPassword() bool {
+	return a.Username != "" && a.Password != ""
+}
+
+// NormalizeAPIToken はカンマ区切りの API トークンから空白と空要素を取り除く
+func NormalizeAPIToken(token string) string {
+	var tokens []string
+	for _, t := range strings.Split(token, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return strings.Join(tokens, ",")
+}
+
+// Deploy mode constants
+const (
+	// DeployModeOverwrite はカスタマイズ設定を kcdev のファイルだけで置き換える
+	DeployModeOverwrite = "overwrite"
+	// DeployModeMerge は kcdev 管理外のファイルや URL を残したまま kcdev のファイルだけを差し替える
+	DeployModeMerge = "merge"
+)
+
+// DeployConfig は dev / deploy 時のカスタマイズ設定の更新方法
+type DeployConfig struct {
+	Mode string `json:"mode,omitempty"`
+	// Position は merge モードで kcdev のファイルを新規追加する位置
+	Position *PositionConfig `json:"position,omitempty"`
+}
+
+// PositionConfig はファイル名または URL の部分一致で配置位置を指定する
+// 例: {"after": ["jquery"], "before": ["common.js"]}
+type PositionConfig struct {
+	After  []string `json:"after,omitempty"`
+	Before []string `json:"before,omitempty"`
+}
+
+// GetDeployMode returns the deploy mode
+// If not set, returns "overwrite" as default
+func (c *Config) GetDeployMode() string {
+	if c.Deploy == nil || c.Deploy.Mode == "" {
+		return DeployModeOverwrite
+	}
+	return c.Deploy.Mode
+}
+
+// DefaultBackupRetention は保持するバックアップ数の既定値
+const DefaultBackupRetention = 10
+
+// BackupConfig はデプロイ前に作成するカスタマイズ設定のバックアップの設定
+type BackupConfig struct {
+	// Retention は保持するバックアップ数
+	Retention int `json:"retention,omitempty"`
+}
+
+// GetBackupRetention returns the number of backups to keep
+// If not set, returns DefaultBackupRetention
+func (c *Config) GetBackupRetention() int {
+	if c.Backup == nil || c.Backup.Retention <= 0 {
+		return DefaultBackupRetention
+	}
+	return c.Backup.Retention
+}
+
+// TypesConfig は kcdev types の設定
+type TypesConfig struct {
+	// RelatedApps はルックアップ・関連レコード一覧の参照先に加えて型を生成するアプリの ID
+	RelatedApps []int `json:"relatedApps,omitempty"`
+}
+
+// GetRelatedApps は型を生成する関連アプリとして config.json に指定したアプリの ID を返す
+func (c *Config) GetRelatedApps() []int {
+	if c.Types == nil {
+		return nil
+	}
+	return c.Types.RelatedApps
+}
+
+// Library position constants
+const (
+	// LibraryBefore は kcdev のファイルより前に読み込む（既定）
+	LibraryBefore = "before"
+	// LibraryAfter は kcdev のファイルより後に読み込む
+	LibraryAfter = "after"
+)
+
+// LibrariesConfig は kcdev のファイルと一緒に登録する外部ライブラリ
+// 各リストは記載順に登録される
+type LibrariesConfig struct {
+	Desktop LibrarySet `json:"desktop"`
+	Mobile  LibrarySet `json:"mobile"`
+}
+
+type LibrarySet struct {
+	JS  []Library `json:"js,omitempty"`
+	CSS []Library `json:"css,omitempty"`
+}
+
+// Library は CDN などの URL、またはプロジェクト内のファイルのどちらか一方を指定する
+type Library struct {
+	URL  string `json:"url,omitempty"`
+	File string `json:"file,omitempty"`
+	// Position は kcdev のファイルとの前後関係（before / after）
+	Position string `json:"position,omitempty"`
+}
+
+// Label は表示用のライブラリ名を返す
+func (l Library) Label() string {
+	if l.URL != "" {
+		return l.URL
+	}
+	return l.File
+}
+
+// IsAfter は kcdev のファイルより後に読み込むかを返す
+func (l Library) IsAfter() bool {
+	return l.Position == LibraryAfter
+}
+
+// IsEmpty は登録されたライブラリが無いかを返す
+func (l *LibrariesConfig) IsEmpty() bool {
+	return l == nil ||
+		len(l.Desktop.JS) == 0 && len(l.Desktop.CSS) == 0 &&
+			len(l.Mobile.JS) == 0 && len(l.Mobile.CSS) == 0
 }
 
 type DevConfig struct {
+	// Origin は開発サーバーの URL（Host / Port が未指定の場合に使う旧形式の設定）
 	Origin string `json:"origin"`
-	Entry  string `json:"entry"`
+	// Host は開発サーバーのホスト名（未指定時は Origin から、それも無ければ localhost）
+	Host string `json:"host,omitempty"`
+	// Port は開発サーバーのポート（未指定時は Origin から、それも無ければ 3000）
+	Port  int    `json:"port,omitempty"`
+	Entry string `json:"entry"`
+	// CertHosts は開発サーバーの証明書の SAN に追加するホスト名・IP アドレス
+	CertHosts []string `json:"certHosts,omitempty"`
+	// SessionToken は開発サーバーがバンドルの配信に kcdev dev ごとのトークンを要求するか
+	SessionToken bool `json:"sessionToken,omitempty"`
+	// Users は dev ローダーを有効にする kintone のログイン名
+	// 空の場合は全ユーザーで開発サーバーを読み込む
+	Users []string `json:"users,omitempty"`
+	// FallbackOnError は開発サーバーに接続できない場合やビルドエラー時に
+	// 最後に kcdev deploy したバンドルを適用するか
+	FallbackOnError bool `json:"fallbackOnError,omitempty"`
+	// ForwardLogs はブラウザの console 出力とエラーを kcdev のターミナルに転送するか（未指定時は true）
+	ForwardLogs *bool `json:"forwardLogs,omitempty"`
+	// Mode は開発時のコードの読み込み方（未指定時は iife）
+	Mode string `json:"mode,omitempty"`
+	// LoaderSchemaVersion は生成する dev ローダーの形式（未指定時は 1、esm モードでは 2）
+	LoaderSchemaVersion int `json:"loaderSchemaVersion,omitempty"`
+}
+
+// Dev server defaults
+const (
+	DefaultDevHost = "localhost"
+	DefaultDevPort = 3000
+)
+
+// GetHost returns the dev server host
+// If not set, returns the host of Origin, or "localhost" as default
+func (d DevConfig) GetHost() string {
+	if d.Host != "" {
+		return d.Host
+	}
+	if u, err := url.Parse(d.Origin); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return DefaultDevHost
+}
+
+// GetPort returns the dev server port
+// If not set, returns the port of Origin, or 3000 as default
+func (d DevConfig) GetPort() int {
+	if d.Port > 0 {
+		return d.Port
+	}
+	if u, err := url.Parse(d.Origin); err == nil {
+		if port, err := strconv.Atoi(u.Port()); err == nil && port > 0 {
+			return port
+		}
+	}
+	return DefaultDevPort
+}
+
+// DevOrigin は Host と Port から開発サーバーのオリジンを返す
+func (d DevConfig) DevOrigin() string {
+	return "https://" + net.JoinHostPort(d.GetHost(), strconv.Itoa(d.GetPort()))
+}
+
+// GetCertHosts は開発サーバーの証明書の SAN に追加するホスト（開発サーバーのホストと CertHosts）を返す
+// localhost と 127.0.0.1 / ::1 は含めない（証明書の発行時に generator 側で常に追加する）
+func (d DevConfig) GetCertHosts() []string {
+	return append([]string{d.GetHost()}, d.CertHosts...)
+}
+
+// SetServer は開発サーバーのホストとポートを設定し、Origin も合わせて更新する
+func (d *DevConfig) SetServer(host string, port int) {
+	d.Host = host
+	d.Port = port
+	d.Origin = d.DevOrigin()
+}
+
+// ValidateDevHost は開発サーバーのホスト名として使えるかチェック
+func ValidateDevHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("ホスト名を入力してください")
+	}
+	if strings.ContainsAny(host, "/:?#@ ") && net.ParseIP(host) == nil {
+		return fmt.Errorf("ホスト名にはスキームやポートを含めないでください")
+	}
+	return nil
+}
+
+// ParseDevPort は開発サーバーのポート番号を解釈する
+func ParseDevPort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("ポートは 1〜65535 の数値で入力してください")
+	}
+	return port, nil
+}
+
+// GetForwardLogs returns whether browser logs are forwarded to the terminal
+// If not set, returns true as default
+func (d DevConfig) GetForwardLogs() bool {
+	return d.ForwardLogs == nil || *d.ForwardLogs
+}
+
+// Loader schema version constants
+const (
+	// LoaderSchemaV1 は同期 XHR と eval でバンドルを実行するローダー
+	LoaderSchemaV1 = 1
+	// LoaderSchemaV2 は script 要素でバンドルを非同期に読み込み、読み込み前のイベントをキューに溜めるローダー
+	LoaderSchemaV2 = 2
+)
+
+// GetLoaderSchemaVersion returns the loader schema version
+// If not set, returns 1 as default (2 in esm mode, which requires it)
+func (d DevConfig) GetLoaderSchemaVersion() int {
+	if d.LoaderSchemaVersion == 0 {
+		if d.GetMode() == DevModeESM {
+			return LoaderSchemaV2
+		}
+		return LoaderSchemaV1
+	}
+	return d.LoaderSchemaVersion
+}
+
+// Dev mode constants
+const (
+	// DevModeIIFE は開発サーバーで IIFE にバンドルしたコードを読み込み、変更時にページをリロードする
+	DevModeIIFE = "iife"
+	// DevModeESM は Vite のモジュールグラフを直接読み込み、HMR で状態を保ったまま差し替える
+	DevModeESM = "esm"
+)
+
+// GetMode returns the dev mode
+// If not set, returns "iife" as default
+func (d DevConfig) GetMode() string {
+	if d.Mode == "" {
+		return DevModeIIFE
+	}
+	return d.Mode
 }
 
 func DefaultConfig() *Config {
-	return &Config{
+	cfg := &Config{
 		Dev: DevConfig{
-			Origin: "https://localhost:3000",
-			Entry:  "/src/main.tsx",
+			Entry: "/src/main.tsx",
 		},
 		Targets: TargetsConfig{
 			Desktop: true,
@@ -59,6 +389,8 @@ func DefaultConfig() *Config {
 		},
 		Scope: ScopeAll,
 	}
+	cfg.Dev.SetServer(DefaultDevHost, DefaultDevPort)
+	return cfg
 }
 
 func ConfigPath(projectDir string) string {
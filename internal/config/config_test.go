@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestNormalizeAPIToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{name: "空", token: "", want: ""},
+		{name: "1 つ", token: "abc", want: "abc"},
+		{name: "前後の空白を取り除く", token: "  abc  ", want: "abc"},
+		{name: "カンマ区切りの空白を取り除く", token: "abc , def,ghi", want: "abc,def,ghi"},
+		{name: "空要素を取り除く", token: ",abc,,def,", want: "abc,def"},
+		{name: "空白だけの要素も取り除く", token: "abc, ,\tdef", want: "abc,def"},
+		{name: "カンマだけ", token: " , ,", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAPIToken(tt.token); got != tt.want {
+				t.Errorf("NormalizeAPIToken(%q) = %q, want %q", tt.token, got, tt.want)
+			}
+		})
+	}
+}
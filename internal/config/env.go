@@ -8,14 +8,26 @@ import (
 )
 
 const (
-	EnvFile         = ".env"
-	EnvKeyUsername  = "KCDEV_USERNAME"
-	EnvKeyPassword  = "KCDEV_PASSWORD"
+	EnvFile        = ".env"
+	EnvKeyUsername = "KCDEV_USERNAME"
+	EnvKeyPassword = "KCDEV_PASSWORD"
+	EnvKeyAPIToken = "KCDEV_API_TOKEN"
+
+	EnvKeyBasicAuthUsername  = "KCDEV_BASIC_AUTH_USERNAME"
+	EnvKeyBasicAuthPassword  = "KCDEV_BASIC_AUTH_PASSWORD"
+	EnvKeyClientCertPassword = "KCDEV_CLIENT_CERT_PASSWORD"
+	EnvKeyProxyPassword      = "KCDEV_PROXY_PASSWORD"
 )
 
 type EnvConfig struct {
 	Username string
 	Password string
+	APIToken string
+
+	BasicAuthUsername  string
+	BasicAuthPassword  string
+	ClientCertPassword string
+	ProxyPassword      string
 }
 
 func LoadEnv(projectDir string) (*EnvConfig, error) {
@@ -30,10 +42,22 @@ func LoadEnv(projectDir string) (*EnvConfig, error) {
 	return &EnvConfig{
 		Username: os.Getenv(EnvKeyUsername),
 		Password: os.Getenv(EnvKeyPassword),
+		APIToken: NormalizeAPIToken(os.Getenv(EnvKeyAPIToken)),
+
+		BasicAuthUsername:  os.Getenv(EnvKeyBasicAuthUsername),
+		BasicAuthPassword:  os.Getenv(EnvKeyBasicAuthPassword),
+		ClientCertPassword: os.Getenv(EnvKeyClientCertPassword),
+		ProxyPassword:      os.Getenv(EnvKeyProxyPassword),
 	}, nil
 }
 
+// HasAuth はいずれかの認証情報が設定されているかチェック
 func (e *EnvConfig) HasAuth() bool {
+	return e.HasPassword() || e.APIToken != ""
+}
+
+// HasPassword はパスワード認証の情報が揃っているかチェック
+func (e *EnvConfig) HasPassword() bool {
 	return e.Username != "" && e.Password != ""
 }
 
@@ -1,81 +1,375 @@
 package generator
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
 	"os"
-	"os/exec"
+	"os/user"
 	"path/filepath"
+	"time"
 
 	"github.com/kintone/kcdev/internal/config"
 )
 
-func GenerateCerts(projectDir string) error {
+const (
+	// caValidity はローカル CA の有効期間
+	caValidity = 10 * 365 * 24 * time.Hour
+	// leafValidity は開発サーバーの証明書の有効期間（Apple の上限 825 日より短くする）
+	leafValidity = 397 * 24 * time.Hour
+	// RenewBefore は有効期限がこれより近い証明書を発行し直す
+	RenewBefore = 30 * 24 * time.Hour
+)
+
+// CADir はユーザーごとのローカル CA の保存先を返す
+func CADir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kcdev", "ca"), nil
+}
+
+// CACertPath はローカル CA の証明書のパスを返す
+func CACertPath() (string, error) {
+	dir, err := CADir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rootCA.pem"), nil
+}
+
+func certPaths(projectDir string) (keyPath, certPath string) {
 	certsDir := filepath.Join(projectDir, config.ConfigDir, "certs")
-	if err := os.MkdirAll(certsDir, 0755); err != nil {
-		return err
+	return filepath.Join(certsDir, "localhost-key.pem"), filepath.Join(certsDir, "localhost.pem")
+}
+
+// CAState は EnsureCA がローカル CA をどう準備したか
+type CAState int
+
+const (
+	// CALoaded は既存のローカル CA を読み込んだ
+	CALoaded CAState = iota
+	// CACreated はローカル CA が無かったため作成した
+	CACreated
+	// CARenewed は期限が近いためローカル CA を作り直した（OS やブラウザに信頼させ直す必要がある）
+	CARenewed
+)
+
+// caBackupSuffix は作り直す前のローカル CA のファイルに付ける拡張子
+const caBackupSuffix = ".old"
+
+// EnsureCA はローカル CA を読み込み、無い場合は作成し、期限が近い場合は作り直す
+// CA のファイルが読めない・壊れている場合は、信頼済みの CA を上書きしないようエラーを返す
+func EnsureCA() (cert *x509.Certificate, key crypto.Signer, state CAState, err error) {
+	dir, err := CADir()
+	if err != nil {
+		return nil, nil, CALoaded, err
 	}
+	certPath := filepath.Join(dir, "rootCA.pem")
+	keyPath := filepath.Join(dir, "rootCA-key.pem")
 
-	keyPath := filepath.Join(certsDir, "localhost-key.pem")
-	certPath := filepath.Join(certsDir, "localhost.pem")
+	cert, key, err = loadCertAndKey(certPath, keyPath)
+	switch {
+	case err == nil && time.Until(cert.NotAfter) > RenewBefore:
+		return cert, key, CALoaded, nil
+	case err == nil:
+		// 期限が近い CA は作り直す。古い CA は .old を付けて残す
+		for _, path := range []string{certPath, keyPath} {
+			if err := os.Rename(path, path+caBackupSuffix); err != nil {
+				return nil, nil, CALoaded, err
+			}
+		}
+		state = CARenewed
+	case fileNotExist(certPath) && fileNotExist(keyPath):
+		state = CACreated
+	default:
+		return nil, nil, CALoaded, fmt.Errorf("ローカル CA を読み込めません（作り直す場合は %s を削除してください）: %w", dir, err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, CALoaded, err
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, CALoaded, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, CALoaded, err
+	}
 
-	if _, err := os.Stat(keyPath); err == nil {
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization:       []string{"kcdev local CA"},
+			OrganizationalUnit: []string{caOwner()},
+			CommonName:         "kcdev local CA " + caOwner(),
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	if err != nil {
+		return nil, nil, CALoaded, err
+	}
+	if err := writeCertAndKey(certPath, keyPath, der, ecKey); err != nil {
+		return nil, nil, CALoaded, err
+	}
+
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, CALoaded, err
+	}
+	return cert, ecKey, state, nil
+}
+
+func fileNotExist(path string) bool {
+	_, err := os.Stat(path)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// caOwner は CA を作成したユーザーとマシンの表示名を返す
+func caOwner() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// GenerateCerts は開発サーバー用の証明書をローカル CA で発行する
+// SAN には localhost と 127.0.0.1 / ::1 に加え、hosts に指定したホストを含める
+// 有効な証明書が既にある場合は何もしない
+func GenerateCerts(projectDir string, hosts ...string) error {
+	if CertsExist(projectDir) {
 		return nil
 	}
+	return RegenerateCerts(projectDir, hosts...)
+}
 
-	opensslConf := `[req]
-distinguished_name = req_distinguished_name
-x509_extensions = v3_req
-prompt = no
+// RegenerateCerts は hosts を SAN に含めて開発サーバーの証明書を発行し直す
+func RegenerateCerts(projectDir string, hosts ...string) error {
+	caCert, caKey, _, err := EnsureCA()
+	if err != nil {
+		return fmt.Errorf("ローカル CA の準備に失敗しました: %w", err)
+	}
 
-[req_distinguished_name]
-CN = localhost
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
 
-[v3_req]
-basicConstraints = CA:FALSE
-keyUsage = critical, digitalSignature, keyEncipherment
-extendedKeyUsage = serverAuth
-subjectAltName = @alt_names
+	dnsNames, ips := certSANs(hosts)
+	now := time.Now()
+	notAfter := now.Add(leafValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"kcdev development certificate"},
+			CommonName:   "localhost",
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    dnsNames,
+		IPAddresses: ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
 
-[alt_names]
-DNS.1 = localhost
-IP.1 = 127.0.0.1
-IP.2 = ::1
-`
+	keyPath, certPath := certPaths(projectDir)
+	if err := os.MkdirAll(filepath.Dir(certPath), 0755); err != nil {
+		return err
+	}
+	return writeCertAndKey(certPath, keyPath, der, key)
+}
+
+// certSANs は証明書の SAN を組み立てる
+func certSANs(hosts []string) ([]string, []net.IP) {
+	dnsNames := []string{"localhost"}
+	ips := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !containsIP(ips, ip) {
+				ips = append(ips, ip)
+			}
+		} else if host != "" && !containsString(dnsNames, host) {
+			dnsNames = append(dnsNames, host)
+		}
+	}
+	return dnsNames, ips
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(list []net.IP, ip net.IP) bool {
+	for _, v := range list {
+		if v.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
 
-	confPath := filepath.Join(certsDir, "openssl.cnf")
-	if err := os.WriteFile(confPath, []byte(opensslConf), 0644); err != nil {
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func writeCertAndKey(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
 		return err
 	}
-	defer os.Remove(confPath)
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+func loadCertAndKey(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	cert, err := loadCert(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s を読み込めません", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s は未対応の鍵の形式です", keyPath)
+	}
+	return cert, signer, nil
+}
+
+func loadCert(certPath string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s を読み込めません", certPath)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
 
-	cmd := exec.Command("openssl", "req",
-		"-x509",
-		"-newkey", "rsa:2048",
-		"-keyout", keyPath,
-		"-out", certPath,
-		"-days", "365",
-		"-nodes",
-		"-config", confPath,
-	)
+// LoadCert は開発サーバーの証明書を読み込む
+func LoadCert(projectDir string) (*x509.Certificate, error) {
+	_, certPath := certPaths(projectDir)
+	return loadCert(certPath)
+}
 
-	output, err := cmd.CombinedOutput()
+// LoadCACert はローカル CA の証明書を読み込む
+func LoadCACert() (*x509.Certificate, error) {
+	certPath, err := CACertPath()
 	if err != nil {
-		return fmt.Errorf("証明書生成エラー: %w\n%s", err, string(output))
+		return nil, err
 	}
+	return loadCert(certPath)
+}
 
-	return nil
+// ExportCA はローカル CA の証明書を dest に書き出す（拡張子が .der / .cer の場合は DER 形式）
+func ExportCA(dest string) error {
+	caCert, _, _, err := EnsureCA()
+	if err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	switch filepath.Ext(dest) {
+	case ".der", ".cer":
+		data = caCert.Raw
+	}
+	return os.WriteFile(dest, data, 0644)
 }
 
+// CertsExist は開発サーバーの鍵と証明書があり、証明書が有効期間内かを返す
 func CertsExist(projectDir string) bool {
-	certsDir := filepath.Join(projectDir, config.ConfigDir, "certs")
-	keyPath := filepath.Join(certsDir, "localhost-key.pem")
-	certPath := filepath.Join(certsDir, "localhost.pem")
-
+	keyPath, _ := certPaths(projectDir)
 	if _, err := os.Stat(keyPath); err != nil {
 		return false
 	}
-	if _, err := os.Stat(certPath); err != nil {
+	cert, err := LoadCert(projectDir)
+	if err != nil {
 		return false
 	}
-	return true
+	now := time.Now()
+	return now.After(cert.NotBefore) && now.Before(cert.NotAfter)
+}
+
+// CertCoversHost は開発サーバーの証明書が host を SAN に含むかを返す
+// 証明書が読めない場合も false を返す
+func CertCoversHost(projectDir, host string) bool {
+	cert, err := LoadCert(projectDir)
+	if err != nil {
+		return false
+	}
+	return cert.VerifyHostname(host) == nil
+}
+
+// CertNeedsRenewal は開発サーバーの証明書を発行し直す必要があるかを判定し、その理由を返す
+// 証明書が無い・期限切れ・期限が近い・ローカル CA が発行していない・hosts を SAN に含まない場合に発行し直す
+func CertNeedsRenewal(projectDir string, hosts []string) (bool, string) {
+	if !CertsExist(projectDir) {
+		return true, "有効な証明書がありません"
+	}
+	cert, err := LoadCert(projectDir)
+	if err != nil {
+		return true, "証明書を読み込めません"
+	}
+	if remaining := time.Until(cert.NotAfter); remaining < RenewBefore {
+		return true, fmt.Sprintf("証明書の有効期限（%s）が近づいています", cert.NotAfter.Local().Format("2006-01-02"))
+	}
+	if caCert, err := LoadCACert(); err != nil || cert.CheckSignatureFrom(caCert) != nil {
+		return true, "証明書がローカル CA で発行されていません"
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return true, fmt.Sprintf("証明書が %s に対応していません", host)
+		}
+	}
+	return false, ""
 }
@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTempCADir はローカル CA の保存先をテスト用の一時ディレクトリにする
+func useTempCADir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	caDir, err := CADir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return caDir
+}
+
+// writeTestCert は projectDir に現在のローカル CA で notAfter まで有効な証明書を書き出す
+func writeTestCert(t *testing.T, projectDir string, notAfter time.Time, hosts ...string) {
+	t.Helper()
+	caCert, caKey, _, err := EnsureCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dnsNames, ips := certSANs(hosts)
+	template := &x509.Certificate{
+		SerialNumber: testSerial(t),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath, certPath := certPaths(projectDir)
+	if err := os.MkdirAll(filepath.Dir(certPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCertAndKey(certPath, keyPath, der, key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testSerial(t *testing.T) *big.Int {
+	t.Helper()
+	serial, err := randomSerial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serial
+}
+
+func TestCertSANs(t *testing.T) {
+	loopback := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+
+	tests := []struct {
+		name      string
+		hosts     []string
+		wantNames []string
+		wantIPs   []net.IP
+	}{
+		{name: "指定なし", wantNames: []string{"localhost"}, wantIPs: loopback},
+		{name: "ホスト名", hosts: []string{"dev.local"}, wantNames: []string{"localhost", "dev.local"}, wantIPs: loopback},
+		{name: "IP アドレス", hosts: []string{"192.168.1.10"}, wantNames: []string{"localhost"}, wantIPs: append(loopback[:2:2], net.ParseIP("192.168.1.10"))},
+		{name: "重複と空文字を除く", hosts: []string{"localhost", "", "127.0.0.1", "dev.local", "dev.local", "::1"}, wantNames: []string{"localhost", "dev.local"}, wantIPs: loopback},
+		{name: "IPv6 アドレス", hosts: []string{"fe80::1"}, wantNames: []string{"localhost"}, wantIPs: append(loopback[:2:2], net.ParseIP("fe80::1"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, ips := certSANs(tt.hosts)
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("certSANs() names = %v, want %v", names, tt.wantNames)
+			}
+			if len(ips) != len(tt.wantIPs) {
+				t.Fatalf("certSANs() ips = %v, want %v", ips, tt.wantIPs)
+			}
+			for i := range ips {
+				if !ips[i].Equal(tt.wantIPs[i]) {
+					t.Errorf("certSANs() ips = %v, want %v", ips, tt.wantIPs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCertNeedsRenewal(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup は projectDir に証明書を用意する
+		setup      func(t *testing.T, projectDir string)
+		hosts      []string
+		want       bool
+		wantReason string
+	}{
+		{
+			name:       "証明書が無い",
+			setup:      func(t *testing.T, projectDir string) {},
+			want:       true,
+			wantReason: "有効な証明書がありません",
+		},
+		{
+			name: "有効な証明書",
+			setup: func(t *testing.T, projectDir string) {
+				if err := RegenerateCerts(projectDir, "dev.local"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			hosts: []string{"localhost", "127.0.0.1", "dev.local"},
+			want:  false,
+		},
+		{
+			name: "ホストを SAN に含まない",
+			setup: func(t *testing.T, projectDir string) {
+				if err := RegenerateCerts(projectDir); err != nil {
+					t.Fatal(err)
+				}
+			},
+			hosts:      []string{"192.168.1.10"},
+			want:       true,
+			wantReason: "192.168.1.10 に対応していません",
+		},
+		{
+			name: "期限切れ",
+			setup: func(t *testing.T, projectDir string) {
+				writeTestCert(t, projectDir, time.Now().Add(-time.Minute))
+			},
+			want:       true,
+			wantReason: "有効な証明書がありません",
+		},
+		{
+			name: "期限が近い",
+			setup: func(t *testing.T, projectDir string) {
+				writeTestCert(t, projectDir, time.Now().Add(RenewBefore-24*time.Hour))
+			},
+			want:       true,
+			wantReason: "有効期限",
+		},
+		{
+			name: "ほかの CA が発行した証明書",
+			setup: func(t *testing.T, projectDir string) {
+				if err := RegenerateCerts(projectDir); err != nil {
+					t.Fatal(err)
+				}
+				// ローカル CA を別の CA に置き換える
+				useTempCADir(t)
+				if _, _, _, err := EnsureCA(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:       true,
+			wantReason: "ローカル CA で発行されていません",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempCADir(t)
+			projectDir := t.TempDir()
+			tt.setup(t, projectDir)
+
+			got, reason := CertNeedsRenewal(projectDir, tt.hosts)
+			if got != tt.want || !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("CertNeedsRenewal() = (%v, %q), want (%v, %q を含む)", got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestEnsureCA(t *testing.T) {
+	t.Run("無ければ作成し、次回は読み込む", func(t *testing.T) {
+		useTempCADir(t)
+		created, _, state, err := EnsureCA()
+		if err != nil || state != CACreated {
+			t.Fatalf("EnsureCA() state = %v, err = %v, want CACreated", state, err)
+		}
+		loaded, _, state, err := EnsureCA()
+		if err != nil || state != CALoaded {
+			t.Fatalf("EnsureCA() state = %v, err = %v, want CALoaded", state, err)
+		}
+		if !loaded.Equal(created) {
+			t.Error("EnsureCA() が既存の CA を作り直しました")
+		}
+	})
+
+	t.Run("壊れている場合は上書きしない", func(t *testing.T) {
+		caDir := useTempCADir(t)
+		if _, _, _, err := EnsureCA(); err != nil {
+			t.Fatal(err)
+		}
+		keyPath := filepath.Join(caDir, "rootCA-key.pem")
+		if err := os.WriteFile(keyPath, []byte("broken"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, _, err := EnsureCA(); err == nil {
+			t.Fatal("EnsureCA() error = nil, want error")
+		}
+		if data, _ := os.ReadFile(keyPath); string(data) != "broken" {
+			t.Error("EnsureCA() が読み込めない CA を上書きしました")
+		}
+	})
+
+	t.Run("片方だけ無い場合は上書きしない", func(t *testing.T) {
+		caDir := useTempCADir(t)
+		if _, _, _, err := EnsureCA(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(filepath.Join(caDir, "rootCA-key.pem")); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, _, err := EnsureCA(); err == nil {
+			t.Fatal("EnsureCA() error = nil, want error")
+		}
+		if fileNotExist(filepath.Join(caDir, "rootCA.pem")) {
+			t.Error("EnsureCA() が CA の証明書を消しました")
+		}
+	})
+
+	t.Run("期限が近い場合は作り直して古い CA を残す", func(t *testing.T) {
+		caDir := useTempCADir(t)
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          testSerial(t),
+			Subject:               pkix.Name{CommonName: "expiring CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(RenewBefore - 24*time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(caDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		certPath := filepath.Join(caDir, "rootCA.pem")
+		if err := writeCertAndKey(certPath, filepath.Join(caDir, "rootCA-key.pem"), der, key); err != nil {
+			t.Fatal(err)
+		}
+
+		cert, _, state, err := EnsureCA()
+		if err != nil || state != CARenewed {
+			t.Fatalf("EnsureCA() state = %v, err = %v, want CARenewed", state, err)
+		}
+		if cert.Subject.CommonName == "expiring CA" {
+			t.Error("EnsureCA() が期限の近い CA を返しました")
+		}
+		old, err := loadCert(certPath + caBackupSuffix)
+		if err != nil || old.Subject.CommonName != "expiring CA" {
+			t.Errorf("古い CA が %s に残っていません: %v", caBackupSuffix, err)
+		}
+	})
+}
@@ -7,25 +7,50 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kintone/kcdev/internal/config"
 	"github.com/kintone/kcdev/internal/prompt"
 )
 
-const (
-	loaderSchemaVersion = 1
-	devOrigin           = "https://localhost:3000"
-)
+// loaderQueueEvents はスキーマ 2 のローダーがバンドルの読み込み完了まで保留する画面表示イベント
+// kintone はカスタマイズの読み込み直後にこれらを発火するため、非同期に読み込むバンドルでは取りこぼす
+var loaderQueueEvents = []string{
+	"app.record.index.show",
+	"app.record.detail.show",
+	"app.record.create.show",
+	"app.record.edit.show",
+	"app.record.print.show",
+	"app.report.show",
+	"portal.show",
+	"space.portal.show",
+	"mobile.app.record.index.show",
+	"mobile.app.record.detail.show",
+	"mobile.app.record.create.show",
+	"mobile.app.record.edit.show",
+	"mobile.app.report.show",
+	"mobile.portal.show",
+	"mobile.space.portal.show",
+}
 
 type LoaderMeta struct {
-	SchemaVersion int           `json:"schemaVersion"`
-	KcdevVersion  string        `json:"kcdevVersion"`
-	GeneratedAt   string        `json:"generatedAt"`
-	Dev           DevMeta       `json:"dev"`
-	Project       ProjectMeta   `json:"project"`
-	Kintone       KintoneMeta   `json:"kintone"`
-	Files         FilesMeta     `json:"files"`
+	SchemaVersion int         `json:"schemaVersion"`
+	KcdevVersion  string      `json:"kcdevVersion"`
+	GeneratedAt   string      `json:"generatedAt"`
+	Dev           DevMeta     `json:"dev"`
+	Project       ProjectMeta `json:"project"`
+	Kintone       KintoneMeta `json:"kintone"`
+	Files         FilesMeta   `json:"files"`
+	// Deployed は最後に kintone に登録したローダーの情報（再生成しても引き継ぐ）
+	Deployed *DeployedMeta `json:"deployed,omitempty"`
+}
+
+// DeployedMeta は kintone に登録済みのローダーの情報
+type DeployedMeta struct {
+	Origin       string `json:"origin"`
+	LoaderSha256 string `json:"loaderSha256"`
+	DeployedAt   string `json:"deployedAt"`
 }
 
 type DevMeta struct {
@@ -51,23 +76,117 @@ type FilesMeta struct {
 	CertCertPath string `json:"certCertPath"`
 }
 
+// LoaderOptions はローダー生成時の設定
+type LoaderOptions struct {
+	Framework   prompt.Framework
+	Language    prompt.Language
+	Output      string
+	ProjectName string
+	Domain      string
+	AppID       int
+	// Users はローダーを有効にするログイン名。空の場合は全ユーザーで有効
+	Users []string
+	// Fallback は Users 以外のユーザーに適用する本番バンドル。nil の場合は何もしない
+	Fallback *LoaderFallback
+	// FallbackOnError は開発サーバーに接続できない場合やビルドエラー時にも Fallback を適用するか
+	FallbackOnError bool
+	// ForwardLogs はブラウザの console 出力とエラーを開発サーバーに送るか
+	ForwardLogs bool
+	// SchemaVersion は生成するローダーの形式（0 の場合は 1）
+	SchemaVersion int
+	// Mode は開発時のコードの読み込み方（iife / esm、空の場合は iife）
+	Mode string
+	// Entry は ESM モードで読み込むエントリー（空の場合はフレームワークと言語から決める）
+	Entry string
+	// Origin は開発サーバーのオリジン（空の場合は https://localhost:3000）
+	Origin string
+}
+
+// LoaderFallback はローダーに埋め込む本番バンドルの内容
+type LoaderFallback struct {
+	JS  string `json:"js,omitempty"`
+	CSS string `json:"css,omitempty"`
+}
+
+// loaderConfig はローダーに JSON として埋め込む設定
+type loaderConfig struct {
+	Origin   string          `json:"origin"`
+	Output   string          `json:"output"`
+	Users    []string        `json:"users"`
+	Fallback *LoaderFallback `json:"fallback"`
+	// FallbackOnError は開発サーバーから読み込めない場合にも Fallback を適用するか
+	FallbackOnError bool `json:"fallbackOnError"`
+	// ForwardLogs はブラウザのログを開発サーバーの /__kcdev/log に送るか
+	ForwardLogs bool `json:"forwardLogs"`
+	// Mode は開発時のコードの読み込み方（iife / esm）
+	Mode string `json:"mode"`
+	// Entry は ESM モードで読み込むエントリーのパス
+	Entry string `json:"entry"`
+	// ReactRefresh は ESM モードで React Fast Refresh のプリアンブルを設定するか
+	ReactRefresh bool `json:"reactRefresh"`
+	// QueueEvents はスキーマ 2 でバンドルの読み込みまで保留するイベント
+	QueueEvents []string `json:"queueEvents,omitempty"`
+}
+
 func GenerateLoader(projectDir string, answers *prompt.InitAnswers) error {
-	return RegenerateLoader(projectDir, answers.Framework, answers.Language, answers.Output, answers.ProjectName, answers.Domain, answers.AppID)
+	return RegenerateLoader(projectDir, LoaderOptions{
+		Framework:   answers.Framework,
+		Language:    answers.Language,
+		Output:      answers.Output,
+		ProjectName: answers.ProjectName,
+		Domain:      answers.Domain,
+		AppID:       answers.AppID,
+	})
 }
 
-// RegenerateLoader はローダーを再生成する（フレームワーク変更時などに使用）
-func RegenerateLoader(projectDir string, framework prompt.Framework, language prompt.Language, output, projectName, domain string, appID int) error {
+// RegenerateLoader はローダーを再生成する（フレームワーク変更時やデプロイ前に使用）
+func RegenerateLoader(projectDir string, opts LoaderOptions) error {
 	managedDir := filepath.Join(projectDir, config.ConfigDir, "managed")
 	if err := os.MkdirAll(managedDir, 0755); err != nil {
 		return err
 	}
 
-	entry := GetEntryPath(framework, language)
-	outputName := output
+	entry := opts.Entry
+	if entry == "" {
+		entry = GetEntryPath(opts.Framework, opts.Language)
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = config.DevModeIIFE
+	}
+	outputName := opts.Output
 	if outputName == "" {
 		outputName = "customize"
 	}
-	loaderContent := generateLoaderContent(entry, outputName)
+	users := opts.Users
+	if users == nil {
+		users = []string{}
+	}
+	origin := opts.Origin
+	if origin == "" {
+		origin = config.DevConfig{}.DevOrigin()
+	}
+	schemaVersion := opts.SchemaVersion
+	if schemaVersion == 0 {
+		schemaVersion = config.LoaderSchemaV1
+	}
+	if mode == config.DevModeESM && schemaVersion < config.LoaderSchemaV2 {
+		return fmt.Errorf("ESM モードにはローダースキーマ 2 が必要です（dev.loaderSchemaVersion を 2 にしてください）")
+	}
+	loaderContent, err := generateLoaderContent(schemaVersion, loaderConfig{
+		Origin:          origin,
+		Output:          outputName,
+		Users:           users,
+		Fallback:        opts.Fallback,
+		FallbackOnError: opts.FallbackOnError,
+		ForwardLogs:     opts.ForwardLogs,
+		Mode:            mode,
+		Entry:           entry,
+		ReactRefresh:    opts.Framework == prompt.FrameworkReact,
+	})
+	if err != nil {
+		return err
+	}
 	loaderPath := filepath.Join(managedDir, "kintone-dev-loader.js")
 
 	if err := os.WriteFile(loaderPath, []byte(loaderContent), 0644); err != nil {
@@ -77,21 +196,21 @@ func RegenerateLoader(projectDir string, framework prompt.Framework, language pr
 	loaderHash := sha256.Sum256([]byte(loaderContent))
 
 	meta := &LoaderMeta{
-		SchemaVersion: loaderSchemaVersion,
+		SchemaVersion: schemaVersion,
 		KcdevVersion:  "0.1.0",
 		GeneratedAt:   time.Now().Format(time.RFC3339),
 		Dev: DevMeta{
-			Origin: devOrigin,
+			Origin: origin,
 			Entry:  entry,
 		},
 		Project: ProjectMeta{
-			Name:      projectName,
-			Framework: string(framework),
-			Language:  string(language),
+			Name:      opts.ProjectName,
+			Framework: string(opts.Framework),
+			Language:  string(opts.Language),
 		},
 		Kintone: KintoneMeta{
-			Domain: domain,
-			AppID:  appID,
+			Domain: opts.Domain,
+			AppID:  opts.AppID,
 		},
 		Files: FilesMeta{
 			LoaderPath:   ".kcdev/managed/kintone-dev-loader.js",
@@ -100,6 +219,9 @@ func RegenerateLoader(projectDir string, framework prompt.Framework, language pr
 			CertCertPath: ".kcdev/certs/localhost.pem",
 		},
 	}
+	if prev, err := LoadLoaderMeta(projectDir); err == nil {
+		meta.Deployed = prev.Deployed
+	}
 
 	metaData, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
@@ -110,30 +232,516 @@ func RegenerateLoader(projectDir string, framework prompt.Framework, language pr
 	return os.WriteFile(metaPath, metaData, 0644)
 }
 
-func generateLoaderContent(entry string, outputName string) string {
+func generateLoaderContent(schemaVersion int, cfg loaderConfig) (string, error) {
+	switch schemaVersion {
+	case config.LoaderSchemaV1:
+		return generateLoaderV1(cfg)
+	case config.LoaderSchemaV2:
+		cfg.QueueEvents = loaderQueueEvents
+		return generateLoaderV2(cfg)
+	default:
+		return "", fmt.Errorf("未対応のローダースキーマバージョンです: %d", schemaVersion)
+	}
+}
+
+// loaderTokenScript は開発サーバーのセッショントークンを受け取り、リクエストに付ける処理
+// kcdev dev が開いたページ（.kcdev/index.html）は kintone に移動するときに URL のフラグメントでトークンを渡す
+// スキーマ 1 / 2 のローダーで共通
+const loaderTokenScript = `  // フラグメントのトークンを kintone のオリジンの localStorage に保存し、URL から取り除く
+  const tokenKey = "kcdev:token:" + config.origin;
+  const tokenParam = /[#&]kcdev-token=([^&]*)/;
+  const tokenMatch = location.hash.match(tokenParam);
+  if (tokenMatch) {
+    try {
+      localStorage.setItem(tokenKey, decodeURIComponent(tokenMatch[1]));
+    } catch (e) {
+      // localStorage が使えない場合はトークンなしで読み込む
+    }
+    const hash = location.hash.replace(tokenParam, "").replace(/^&/, "#");
+    history.replaceState(history.state, "", location.pathname + location.search + (hash === "#" ? "" : hash));
+  }
+  let sessionToken = null;
+  try {
+    sessionToken = localStorage.getItem(tokenKey);
+  } catch (e) {
+    // 同上
+  }
+  const withToken = (url) =>
+    sessionToken ? url + (url.includes("?") ? "&" : "?") + "kcdev_token=" + encodeURIComponent(sessionToken) : url;
+  // Vite のモジュールの URL（トークンを要求する開発サーバーは、トークンを含むパスの下でモジュールを配信する）
+  const moduleURL = (path) =>
+    config.origin + (sessionToken ? "/__kcdev/s/" + encodeURIComponent(sessionToken) : "") + path;
+`
+
+// loaderStatusScript は開発サーバーから読み込めなかった原因を表示する処理
+// 接続できない場合はバナー、ビルドエラーは Vite と同様のオーバーレイで表示する
+// スキーマ 1 / 2 のローダーで共通
+const loaderStatusScript = `  const whenBody = (render) => {
+    if (document.body) render();
+    else document.addEventListener("DOMContentLoaded", render);
+  };
+
+  // 開発サーバーの問題を画面上部のバナーで知らせる（× で閉じられる）
+  const showBanner = (message) => {
+    whenBody(() => {
+      const banner = document.createElement("div");
+      banner.setAttribute("data-kcdev-banner", "");
+      banner.style.cssText =
+        "position:fixed;top:0;left:50%;transform:translateX(-50%);z-index:10000;display:flex;gap:8px;align-items:center;" +
+        "padding:6px 12px;background:#fff4e5;color:#663c00;border:1px solid #ffb74d;border-top:none;" +
+        "border-radius:0 0 4px 4px;font-size:12px;box-shadow:0 1px 4px rgba(0,0,0,.2)";
+      const text = document.createElement("span");
+      text.textContent = "[kcdev] " + message;
+      const close = document.createElement("button");
+      close.type = "button";
+      close.textContent = "×";
+      close.style.cssText = "border:none;background:none;cursor:pointer;font-size:14px;color:inherit";
+      close.onclick = () => banner.remove();
+      banner.append(text, close);
+      document.body.appendChild(banner);
+    });
+  };
+
+  // ビルドエラーをオーバーレイで表示する（クリックか Esc で閉じ、次のビルド成功で自動的に消える）
+  let overlay = null;
+  const clearOverlay = () => {
+    if (overlay) overlay.remove();
+    overlay = null;
+  };
+  const showOverlay = (error, note) => {
+    whenBody(() => {
+      clearOverlay();
+      overlay = document.createElement("div");
+      overlay.setAttribute("data-kcdev-overlay", "");
+      overlay.style.cssText =
+        "position:fixed;inset:0;z-index:10001;overflow:auto;background:rgba(0,0,0,.66);" +
+        "font:13px/1.5 ui-monospace,SFMono-Regular,Menlo,Consolas,monospace";
+      const panel = document.createElement("div");
+      panel.style.cssText =
+        "max-width:960px;margin:60px auto;padding:24px 28px;background:#181818;color:#d8d8d8;" +
+        "border-top:6px solid #ff5555;border-radius:6px;box-shadow:0 4px 16px rgba(0,0,0,.4)";
+      const block = (text, css) => {
+        const el = document.createElement("pre");
+        el.style.cssText = "margin:0 0 12px;white-space:pre-wrap;word-break:break-word;" + css;
+        el.textContent = text;
+        panel.appendChild(el);
+      };
+      if (error.plugin) block("[plugin:" + error.plugin + "]", "color:#cfa4ff");
+      block(error.message, "color:#ff5555;font-weight:bold");
+      if (error.file) {
+        const position = error.line ? ":" + error.line + (error.column != null ? ":" + error.column : "") : "";
+        block(error.file + position, "color:#2dd9da;text-decoration:underline");
+      }
+      if (error.frame) block(error.frame, "color:#ffcc66;background:#222;padding:12px;border-radius:4px");
+      block("[kcdev] ファイルを保存して再ビルドに成功すると自動的に閉じます。クリックまたは Esc で閉じます" + (note || ""), "color:#888;font-size:12px");
+      panel.onclick = (e) => e.stopPropagation();
+      overlay.onclick = clearOverlay;
+      overlay.appendChild(panel);
+      document.body.appendChild(overlay);
+    });
+  };
+  document.addEventListener("keydown", (e) => {
+    if (e.key === "Escape") clearOverlay();
+  });
+
+  const fallbackNote = (fallbackApplied) =>
+    fallbackApplied ? "（最後にデプロイしたバンドルで表示しています）" : "（カスタマイズは適用されていません）";
+
+  // 開発サーバーの状態を問い合わせ、接続できないのかビルドエラーなのかを表示する
+  const reportDevError = (fallbackApplied) => {
+    const note = fallbackNote(fallbackApplied);
+    fetch(withToken(config.origin + "/__kcdev/status"), { cache: "no-store" })
+      .then((res) => (res.ok ? res.json() : {}))
+      .then((status) => {
+        if (status.error) showOverlay(status.error, note);
+        else showBanner("ビルドエラー: バンドルを生成できませんでした" + note);
+      })
+      .catch(() => {
+        showBanner("開発サーバー（" + config.origin + "）に接続できません。kcdev dev を起動してください" + note);
+      });
+  };
+
+  // HMR: @vite/client を非同期で読み込んでリロードを検知し、再ビルドの結果でオーバーレイを更新する
+  const watchBuild = () => {
+    import(moduleURL("/@vite/client"))
+      .then((client) => {
+        if (!client.createHotContext) return;
+        const hot = client.createHotContext("/@kcdev/loader");
+        hot.on("kcdev:build-error", (error) => showOverlay(error));
+        hot.on("kcdev:build-ok", clearOverlay);
+      })
+      .catch(() => {});
+  };
+`
+
+// loaderLogScript は console 出力とキャッチされなかったエラーを開発サーバーに送る処理
+// 開発サーバーは受け取ったログを kcdev のターミナルに転送する
+const loaderLogScript = `  // console.* と window のエラーを /__kcdev/log に送る
+  // 同期的に実行中の kintone イベントハンドラーがあれば、そのイベント名を付ける
+  const forwardLogs = () => {
+    let currentEvent = null;
+
+    const format = (value) => {
+      if (typeof value === "string") return value;
+      if (value instanceof Error) return value.stack || String(value);
+      if (value === undefined) return "undefined";
+      try {
+        return JSON.stringify(value);
+      } catch (e) {
+        return String(value);
+      }
+    };
+
+    const send = (level, args, stack) => {
+      const entry = {
+        level: level,
+        args: args.map(format),
+        url: location.href,
+        event: currentEvent,
+        stack: stack || null,
+      };
+      fetch(withToken(config.origin + "/__kcdev/log"), {
+        method: "POST",
+        body: JSON.stringify(entry),
+        keepalive: true,
+        mode: "no-cors",
+      }).catch(() => {});
+    };
+
+    ["log", "info", "warn", "error", "debug"].forEach((level) => {
+      const original = console[level];
+      console[level] = function (...args) {
+        send(level, args);
+        return original.apply(this, args);
+      };
+    });
+
+    window.addEventListener("error", (e) => {
+      send("error", [e.message], e.error && e.error.stack);
+    });
+    window.addEventListener("unhandledrejection", (e) => {
+      send("error", ["Unhandled rejection: " + format(e.reason)], e.reason && e.reason.stack);
+    });
+
+    const events = kintone.events;
+    const on = events.on;
+    events.on = function (types, handler) {
+      const tracked = function (event) {
+        const previous = currentEvent;
+        currentEvent = event && event.type;
+        try {
+          return handler.apply(this, arguments);
+        } finally {
+          currentEvent = previous;
+        }
+      };
+      return on.call(events, types, tracked);
+    };
+  };
+`
+
+// generateLoaderV1 は同期 XHR で取得したバンドルを eval で実行するローダーを生成する
+func generateLoaderV1(cfg loaderConfig) (string, error) {
 	now := time.Now().Format(time.RFC3339)
 
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+
 	return fmt.Sprintf(`// kcdev-loader
 // schemaVersion: %d
 // generatedAt: %s
 // origin: %s
 
 (() => {
-  const origin = "%s";
+  const config = %s;
+
+%s
+  // 本番バンドルを適用する（埋め込まれていない場合は false）
+  const applyFallback = () => {
+    const fallback = config.fallback;
+    if (!fallback) return false;
+    if (fallback.css) {
+      const style = document.createElement("style");
+      style.textContent = fallback.css;
+      document.head.appendChild(style);
+    }
+    if (fallback.js) {
+      (0, eval)(fallback.js);
+    }
+    return true;
+  };
+
+  // 対象ユーザー以外には開発サーバーを読み込まず、本番バンドルを適用する
+  const user = kintone.getLoginUser();
+  if (config.users.length > 0 && !config.users.includes(user.code)) {
+    applyFallback();
+    return;
+  }
+
+%s
+%s
+  if (config.forwardLogs) forwardLogs();
+
+  const origin = config.origin;
   const t = Date.now();
 
   // 同期 XHR で IIFE バンドルを取得して実行
+  // 開発サーバーが起動していない場合、send は例外を投げる
   const xhr = new XMLHttpRequest();
-  xhr.open("GET", origin + "/%s.js?t=" + t, false);
-  xhr.send();
+  xhr.open("GET", withToken(origin + "/" + config.output + ".js?t=" + t), false);
+  try {
+    xhr.send();
+  } catch (e) {
+    // status が 0 のまま下で処理する
+  }
   if (xhr.status === 200) {
     eval(xhr.responseText);
+  } else {
+    const fallbackApplied = config.fallbackOnError && applyFallback();
+    let error = null;
+    try {
+      error = xhr.status === 500 || xhr.status === 403 ? JSON.parse(xhr.responseText) : null;
+    } catch (e) {
+      // 古い vite.config.ts はエラーを JSON で返さない
+    }
+    if (error && error.message) showOverlay(error, fallbackNote(fallbackApplied));
+    else reportDevError(fallbackApplied);
   }
 
-  // HMR: @vite/client を非同期で読み込んでリロード検知
-  import(origin + "/@vite/client").catch(() => {});
+  watchBuild();
 })();
-`, loaderSchemaVersion, now, devOrigin, devOrigin, outputName)
+`, config.LoaderSchemaV1, now, cfg.Origin, configJSON, loaderTokenScript, loaderStatusScript, loaderLogScript), nil
+}
+
+// generateLoaderV2 は script 要素でバンドルを非同期に読み込むローダーを生成する
+// 同期 XHR と eval を使わないため、メインスレッドを止めず CSP の unsafe-eval も不要
+func generateLoaderV2(cfg loaderConfig) (string, error) {
+	now := time.Now().Format(time.RFC3339)
+
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`// kcdev-loader
+// schemaVersion: %d
+// generatedAt: %s
+// origin: %s
+
+(() => {
+  const config = %s;
+
+%s
+  const events = kintone.events;
+
+  // 読み込み完了までに発火した画面表示イベントを保留し、読み込み中に登録されたハンドラーで後から処理する
+  // 戻り値の関数を呼ぶと保留を解除する
+  const holdEvents = () => {
+    const on = events.on;
+    const handlers = {};
+    const replayed = new WeakSet();
+    let loaded = false;
+    let resolveReady;
+    const ready = new Promise((resolve) => {
+      resolveReady = resolve;
+    });
+
+    // 保留したイベントを二重に処理しないよう、読み込み中に登録されたハンドラーは包んで登録する
+    events.on = function (types, handler) {
+      if (loaded) return on.call(events, types, handler);
+      const guarded = (event) => (replayed.has(event) ? event : handler(event));
+      [].concat(types).forEach((type) => {
+        (handlers[type] = handlers[type] || []).push(handler);
+      });
+      return on.call(events, types, guarded);
+    };
+
+    const dispatch = (event) => {
+      replayed.add(event);
+      return (handlers[event.type] || []).reduce(
+        (prev, handler) =>
+          prev.then((current) =>
+            Promise.resolve(handler(current)).then((result) => {
+              const next = result === undefined ? current : result;
+              if (next && typeof next === "object") replayed.add(next);
+              return next;
+            })
+          ),
+        Promise.resolve(event)
+      );
+    };
+
+    on.call(events, config.queueEvents, (event) => {
+      if (loaded) return event;
+      return ready.then(() => dispatch(event));
+    });
+
+    return () => {
+      loaded = true;
+      resolveReady();
+    };
+  };
+
+  const loadScript = (src) =>
+    new Promise((resolve, reject) => {
+      const script = document.createElement("script");
+      script.src = src;
+      script.onload = resolve;
+      script.onerror = () => {
+        console.error("[kcdev] バンドルを読み込めませんでした: " + src);
+        reject();
+      };
+      document.head.appendChild(script);
+    });
+
+  // バンドルを script 要素で読み込む
+  // sources を先頭から順に試し、読み込めなかったものごとに onError を呼ぶ
+  const loadBundle = (sources, onError) => {
+    const release = holdEvents();
+    const load = (index) => {
+      if (index >= sources.length) {
+        release();
+        return;
+      }
+      loadScript(sources[index]).then(release, () => {
+        if (onError) onError(index);
+        load(index + 1);
+      });
+    };
+    load(0);
+  };
+
+  // 本番バンドルを適用し、JS の Blob URL を返す（埋め込まれていない場合は null）
+  const fallbackSource = () => {
+    const fallback = config.fallback;
+    if (!fallback) return null;
+    if (fallback.css) {
+      const style = document.createElement("style");
+      style.textContent = fallback.css;
+      document.head.appendChild(style);
+    }
+    if (!fallback.js) return null;
+    return URL.createObjectURL(new Blob([fallback.js], { type: "text/javascript" }));
+  };
+
+  // 対象ユーザー以外には開発サーバーを読み込まず、本番バンドルを適用する
+  const user = kintone.getLoginUser();
+  if (config.users.length > 0 && !config.users.includes(user.code)) {
+    const src = fallbackSource();
+    if (src) loadBundle([src]);
+    return;
+  }
+
+%s
+%s
+  // ESM モード: Vite のモジュールグラフを直接読み込み、コンポーネントや CSS を HMR で差し替える
+  // エントリーが再実行されたときは古いハンドラーを無効にし、直前のイベントで新しいハンドラーを呼び直す
+  // （kintone のイベントを再発火せずに、スペース要素などへ描画し直せる）
+  const loadModules = () => {
+    const on = events.on;
+    const registered = [];
+    let generation = 0;
+    let lastEvent = null;
+    let remountPending = false;
+
+    on.call(events, config.queueEvents, (event) => {
+      lastEvent = event;
+      return event;
+    });
+
+    events.on = function (types, handler) {
+      const entry = { types: [].concat(types), handler: handler, generation: generation };
+      registered.push(entry);
+      return on.call(events, types, function (event) {
+        return entry.generation === generation ? handler.apply(this, arguments) : event;
+      });
+    };
+
+    // dev サーバーがエントリーに追加する HMR のコードから呼ばれる
+    window.__kcdev__ = {
+      dispose: () => {
+        generation++;
+        remountPending = true;
+      },
+      remount: () => {
+        if (!remountPending) return;
+        remountPending = false;
+        if (!lastEvent) return;
+        registered
+          .filter((entry) => entry.generation === generation && entry.types.includes(lastEvent.type))
+          .forEach((entry) => {
+            try {
+              entry.handler(lastEvent);
+            } catch (e) {
+              console.error(e);
+            }
+          });
+      },
+    };
+
+    const release = holdEvents();
+
+    // React Fast Refresh のプリアンブル（index.html の代わりにローダーで設定する）
+    const preamble = config.reactRefresh
+      ? import(moduleURL("/@react-refresh")).then((runtime) => {
+          runtime.default.injectIntoGlobalHook(window);
+          window.$RefreshReg$ = () => {};
+          window.$RefreshSig$ = () => (type) => type;
+          window.__vite_plugin_react_preamble_installed__ = true;
+        })
+      : Promise.resolve();
+
+    preamble
+      .then(() => import(moduleURL("/@vite/client")))
+      .then(() => import(moduleURL(config.entry)))
+      .catch((e) => {
+        console.error("[kcdev] モジュールを読み込めませんでした", e);
+        const src = config.fallbackOnError ? fallbackSource() : null;
+        reportDevError(!!src);
+        if (src) return loadScript(src).catch(() => {});
+      })
+      .finally(release);
+  };
+
+  if (config.mode === "esm") {
+    loadModules();
+  } else {
+    // 開発サーバーから読み込めなかった場合は、設定に応じて本番バンドルに切り替える
+    const sources = [withToken(config.origin + "/" + config.output + ".js?t=" + Date.now())];
+    let fallbackApplied = false;
+    loadBundle(sources, (index) => {
+      if (index !== 0) return;
+      if (config.fallbackOnError && config.fallback) {
+        const src = fallbackSource();
+        if (src) sources.push(src);
+        fallbackApplied = true;
+      }
+      reportDevError(fallbackApplied);
+    });
+  }
+
+  // holdEvents の後に差し替え、保留したイベントの再実行にもイベント名を付ける
+  if (config.forwardLogs) forwardLogs();
+
+  watchBuild();
+})();
+`, config.LoaderSchemaV2, now, cfg.Origin, configJSON, loaderTokenScript, loaderStatusScript, loaderLogScript), nil
+}
+
+// loaderSchemaVersionOf はローダーのヘッダーコメントからスキーマバージョンを読み取る
+func loaderSchemaVersionOf(content []byte) int {
+	for _, line := range strings.Split(string(content), "\n") {
+		var version int
+		if _, err := fmt.Sscanf(line, "// schemaVersion: %d", &version); err == nil {
+			return version
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+	}
+	return 0
 }
 
 func LoadLoaderMeta(projectDir string) (*LoaderMeta, error) {
@@ -151,7 +759,45 @@ func LoadLoaderMeta(projectDir string) (*LoaderMeta, error) {
 	return &meta, nil
 }
 
-func VerifyLoader(projectDir string) (bool, string, error) {
+// MarkLoaderDeployed は現在のローダーを kintone に登録したことをメタデータに記録する
+func MarkLoaderDeployed(projectDir string) error {
+	meta, err := LoadLoaderMeta(projectDir)
+	if err != nil {
+		return err
+	}
+	meta.Deployed = &DeployedMeta{
+		Origin:       meta.Dev.Origin,
+		LoaderSha256: meta.Files.LoaderSha256,
+		DeployedAt:   time.Now().Format(time.RFC3339),
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	metaPath := filepath.Join(projectDir, config.ConfigDir, "managed", "loader.meta.json")
+	return os.WriteFile(metaPath, data, 0644)
+}
+
+// DeployedLoaderStale は kintone に登録済みのローダーが現在の開発サーバーのオリジンと
+// 一致しないかを判定し、再登録が必要な理由を返す
+// 登録の記録が無い場合は、最後に生成したローダーが登録されているものとみなす
+func DeployedLoaderStale(projectDir, origin string) (bool, string) {
+	meta, err := LoadLoaderMeta(projectDir)
+	if err != nil {
+		return false, ""
+	}
+	deployedOrigin := meta.Dev.Origin
+	if meta.Deployed != nil {
+		deployedOrigin = meta.Deployed.Origin
+	}
+	if deployedOrigin != origin {
+		return true, fmt.Sprintf("開発サーバーのオリジンが %s から %s に変更されています", deployedOrigin, origin)
+	}
+	return false, ""
+}
+
+func VerifyLoader(projectDir, origin string) (bool, string, error) {
 	meta, err := LoadLoaderMeta(projectDir)
 	if err != nil {
 		return false, "メタデータが見つかりません", nil
@@ -163,10 +809,24 @@ func VerifyLoader(projectDir string) (bool, string, error) {
 		return false, "ローダーファイルが見つかりません", nil
 	}
 
+	metaVersion := meta.SchemaVersion
+	if metaVersion != config.LoaderSchemaV1 && metaVersion != config.LoaderSchemaV2 {
+		return false, fmt.Sprintf("未対応のスキーマバージョンです（%d）", metaVersion), nil
+	}
+	if version := loaderSchemaVersionOf(content); version != metaVersion {
+		return false, "ローダーとメタデータのスキーマバージョンが一致しません。再登録が必要です", nil
+	}
+
 	hash := sha256.Sum256(content)
 	if hex.EncodeToString(hash[:]) != meta.Files.LoaderSha256 {
 		return false, "ローダーが変更されています。再登録が必要です", nil
 	}
+	if meta.Dev.Origin != origin {
+		return false, fmt.Sprintf("ローダーのオリジン（%s）が設定（%s）と一致しません。再登録が必要です", meta.Dev.Origin, origin), nil
+	}
+	if meta.Deployed != nil && meta.Deployed.LoaderSha256 != meta.Files.LoaderSha256 {
+		return false, "登録済みのローダーと内容が異なります。再登録が必要です", nil
+	}
 
-	return true, "OK（再登録不要）", nil
+	return true, fmt.Sprintf("OK（スキーマ %d、再登録不要）", metaVersion), nil
 }
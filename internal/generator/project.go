@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kintone/kcdev/internal/config"
 	"github.com/kintone/kcdev/internal/prompt"
 )
 
@@ -26,7 +27,8 @@ func GenerateProject(projectDir string, answers *prompt.InitAnswers) error {
 		return err
 	}
 
-	if err := generateIndexHTML(projectDir, answers); err != nil {
+	kintoneCfg := config.KintoneConfig{Domain: answers.Domain, AppID: answers.AppID, GuestSpaceID: answers.GuestSpaceID}
+	if err := GenerateIndexHTML(projectDir, kintoneCfg.AppURL()); err != nil {
 		return err
 	}
 
@@ -42,21 +44,25 @@ func GenerateProject(projectDir string, answers *prompt.InitAnswers) error {
 		return err
 	}
 
-	// TypeScript の場合は tsconfig.json と型定義プレースホルダーを生成
+	// TypeScript の場合は tsconfig.json、JavaScript の場合は jsconfig.json と型定義プレースホルダーを生成
 	if answers.Language == prompt.LanguageTypeScript {
 		if err := generateTSConfig(projectDir, answers.Framework); err != nil {
 			return err
 		}
-		if err := generateTypesPlaceholder(projectDir); err != nil {
+	} else {
+		if err := GenerateJSConfig(projectDir, answers.Framework); err != nil {
 			return err
 		}
 	}
+	if err := generateTypesPlaceholder(projectDir); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func generateIndexHTML(projectDir string, answers *prompt.InitAnswers) error {
-	appURL := fmt.Sprintf("https://%s/k/%d/", answers.Domain, answers.AppID)
+// GenerateIndexHTML は SSL 許可後に kintone アプリへリダイレクトする開発用ページを生成する
+func GenerateIndexHTML(projectDir string, appURL string) error {
 	content := fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <head>
@@ -99,8 +105,15 @@ func generateIndexHTML(projectDir string, answers *prompt.InitAnswers) error {
     <a href="%s">kintoneアプリを開く</a>
   </div>
   <script>
+    // 開発サーバーがセッショントークンを要求する場合、kcdevToken を埋め込んで配信する
+    // ローダーが kintone 側で読み取れるよう、URL のフラグメントで渡す
+    var url = "%s";
+    if (window.kcdevToken) {
+      url += "#kcdev-token=" + encodeURIComponent(window.kcdevToken);
+      document.querySelector("a").href = url;
+    }
     setTimeout(function() {
-      window.location.href = "%s";
+      window.location.href = url;
     }, 1500);
   </script>
 </body>
@@ -181,11 +194,7 @@ func generatePackageJSON(projectDir string, answers *prompt.InitAnswers) error {
 		Deploy:        "kcdev deploy",
 		DeployPreview: "kcdev deploy --preview",
 		Lint:          "eslint --config .kcdev/eslint.config.js src/",
-	}
-
-	// TypeScript の場合は types スクリプトを追加
-	if answers.Language == prompt.LanguageTypeScript {
-		scripts.Types = "kcdev types"
+		Types:         "kcdev types",
 	}
 
 	// dependencies は空で生成（npm install で最新版を追加）
@@ -210,10 +219,11 @@ func generatePackageJSON(projectDir string, answers *prompt.InitAnswers) error {
 // GetPackageList はフレームワークと言語に応じたパッケージ名リストを返す（バージョンなし）
 func GetPackageList(framework prompt.Framework, language prompt.Language) (deps []string, devDeps []string) {
 	// 共通 devDependencies
-	devDeps = append(devDeps, "vite", "eslint", "@eslint/js", "globals")
+	// @kintone/dts-gen は kintone の JavaScript API の型（kintone.d.ts）に使う。フィールドの型は kcdev types が生成する
+	devDeps = append(devDeps, "vite", "eslint", "@eslint/js", "globals", "@kintone/dts-gen")
 
 	if language == prompt.LanguageTypeScript {
-		devDeps = append(devDeps, "typescript", "@kintone/dts-gen", "typescript-eslint")
+		devDeps = append(devDeps, "typescript", "typescript-eslint")
 	}
 
 	switch framework {
@@ -297,6 +307,36 @@ func generateTSConfig(projectDir string, framework prompt.Framework) error {
 	return os.WriteFile(filepath.Join(projectDir, "tsconfig.json"), []byte(content), 0644)
 }
 
+// GenerateJSConfig は JavaScript プロジェクトのエディター補完と checkJs 用の jsconfig.json を生成する
+// フィールドの型は kcdev types が生成する src/types/kintone.d.ts から読み込む
+func GenerateJSConfig(projectDir string, framework prompt.Framework) error {
+	jsx := ""
+	if framework == prompt.FrameworkReact {
+		jsx = "\n    \"jsx\": \"react-jsx\","
+	}
+
+	content := fmt.Sprintf(`{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "ESNext",
+    "moduleResolution": "bundler",
+    "checkJs": true,%s
+    "skipLibCheck": true,
+    "types": []
+  },
+  "files": [
+    "./node_modules/@kintone/dts-gen/kintone.d.ts",
+    "./src/types/kintone.d.ts"
+  ],
+  "include": [
+    "src/**/*"
+  ]
+}
+`, jsx)
+
+	return os.WriteFile(filepath.Join(projectDir, "jsconfig.json"), []byte(content), 0644)
+}
+
 func generateGitignore(projectDir string) error {
 	content := `# Dependencies
 node_modules/
@@ -311,6 +351,10 @@ dist/
 # kcdev (sensitive)
 .kcdev/config.json
 .kcdev/certs/
+.kcdev/backups/
+.kcdev/dev-session.json
+.kcdev/deploy-state.json
+.kcdev/deploy-state/
 
 # IDE
 .vscode/
@@ -24,7 +24,7 @@ func generateViteConfigContent(framework prompt.Framework, language prompt.Langu
 	plugins := getVitePlugins(framework)
 	entry := GetEntryPath(framework, language)
 
-	return fmt.Sprintf(`import { defineConfig, build } from 'vite'
+	return fmt.Sprintf(`import { defineConfig, build, normalizePath } from 'vite'
 %s
 import fs from 'fs'
 import path from 'path'
@@ -38,38 +38,328 @@ const srcEntry = path.resolve(__dirname, '..%s')
 const configPath = path.resolve(__dirname, 'config.json')
 const config = JSON.parse(fs.readFileSync(configPath, 'utf-8'))
 const outputName = config.output || 'customize'
+// 開発時のコードの読み込み方（iife: バンドルしてリロード / esm: モジュールグラフを HMR）
+const devMode = config.dev?.mode || 'iife'
+const devEntry = config.dev?.entry ? path.resolve(projectRoot, '.' + config.dev.entry) : srcEntry
+// 開発サーバーのホストとポート（dev.host / dev.port、未指定時は dev.origin から）
+// kcdev dev --lan では KCDEV_DEV_HOST に LAN の IP アドレスが渡される
+const legacyOrigin = new URL(config.dev?.origin || 'https://localhost:3000')
+const devHost = process.env.KCDEV_DEV_HOST || config.dev?.host || legacyOrigin.hostname.replace(/^\[|\]$/g, '')
+const devPort = Number(config.dev?.port || legacyOrigin.port || 3000)
+const devOrigin = 'https://' + (devHost.includes(':') ? '[' + devHost + ']' : devHost) + ':' + devPort
+
+// CORS を許可する kintone のオリジン（kcdev dev が KCDEV_ALLOWED_ORIGINS で渡す。直接起動した場合は config.json のドメイン）
+const allowedOrigins = (process.env.KCDEV_ALLOWED_ORIGINS || (config.kintone?.domain ? 'https://' + config.kintone.domain : ''))
+  .split(',')
+  .filter(Boolean)
+// バンドルの配信に要求するセッショントークン（dev.sessionToken が有効な場合に kcdev dev が渡す）
+const sessionToken = process.env.KCDEV_SESSION_TOKEN || ''
+// Vite のモジュールを配信するパス（トークンを要求する場合はトークンを含め、ESM モードの import もトークンで保護する）
+const sessionBase = sessionToken ? '/__kcdev/s/' + sessionToken + '/' : '/'
+
+// リクエストにセッショントークンが付いているか（トークンを要求しない場合は常に true）
+function hasSessionToken(req): boolean {
+  if (!sessionToken) return true
+  const token = new URL(req.url ?? '/', devOrigin).searchParams.get('kcdev_token')
+  return token === sessionToken
+}
+
+// ほかのサイトから偽のログを送り込めないよう、kintone のオリジンからトークン付きで送られたログだけを受け付ける
+function isAllowedLogRequest(req): boolean {
+  const origin = req.headers.origin
+  return req.method === 'POST' && !!origin && allowedOrigins.includes(origin) && hasSessionToken(req)
+}
+
+// 開発マシン自身からのリクエストか（--lan で LAN の IP アドレスに接続した場合を含む）
+// LAN のほかの端末にはトークンを渡さない
+function isLocalRequest(req): boolean {
+  const remote = req.socket?.remoteAddress
+  return !!remote && (remote === req.socket.localAddress || ['127.0.0.1', '::1', '::ffff:127.0.0.1'].includes(remote))
+}
+
+function sessionTokenError(): BuildError {
+  return toBuildError(
+    new Error(
+      'セッショントークンがないため、開発サーバーがバンドルの配信を拒否しました。' +
+        devOrigin + ' を開き、そこから kintone に移動し直してください'
+    )
+  )
+}
 
 // プロジェクトルートにindex.htmlがあるか確認
 const hasRootIndexHtml = fs.existsSync(path.join(projectRoot, 'index.html'))
 
 let cachedBundle: string | null = null
+// 直近のビルドエラー（ローダーのバナー・オーバーレイ表示用）
+let lastBuildError: BuildError | null = null
+
+// ローダーのオーバーレイに渡すビルドエラー
+interface BuildError {
+  message: string
+  file: string | null
+  line: number | null
+  column: number | null
+  frame: string | null
+  plugin: string | null
+}
+
+// Rollup / Vite のエラーからファイル位置とコードフレームを取り出す
+function toBuildError(err: any): BuildError {
+  const loc = err?.loc
+  const file = loc?.file ?? err?.id ?? null
+  return {
+    message: String(err?.message ?? err),
+    file: file ? path.relative(projectRoot, file) : null,
+    line: loc?.line ?? null,
+    column: loc?.column ?? null,
+    frame: err?.frame ?? null,
+    plugin: err?.plugin ?? null,
+  }
+}
+
+// ビルド中は building が解決されるまで /${outputName}.js の応答を待たせる
+let building: Promise<void> | null = null
+let finishBuilding: (() => void) | null = null
+
+function startBuilding() {
+  if (!building) {
+    building = new Promise((resolve) => {
+      finishBuilding = resolve
+    })
+  }
+}
+
+function endBuilding() {
+  finishBuilding?.()
+  building = null
+  finishBuilding = null
+}
+
+// ビルド結果を取り込むプラグイン
+// CSS とソースマップをインライン化して cachedBundle に保存し、ファイルには書き出さない
+const captureBundlePlugin = {
+  name: 'kcdev-capture-bundle',
+  generateBundle(_options, bundle) {
+    const chunks = Object.values(bundle) as any[]
+    const jsChunk = chunks.find((o) => o.type === 'chunk' && o.fileName === outputName + '.js')
+    const cssAsset = chunks.find((o) => o.type === 'asset' && o.fileName.endsWith('.css'))
+    for (const key of Object.keys(bundle)) {
+      delete bundle[key]
+    }
+    if (!jsChunk) {
+      throw new Error('Build output not found')
+    }
+
+    let code = jsChunk.code.replace(/\n?\/\/# sourceMappingURL=.*$/, '')
+    const map = jsChunk.map ? JSON.parse(jsChunk.map.toString()) : null
+
+    // CSS をインライン化（1 行追加する分、ソースマップを 1 行ずらす）
+    if (cssAsset) {
+      const css = typeof cssAsset.source === 'string' ? cssAsset.source : Buffer.from(cssAsset.source).toString('utf-8')
+      code = `+"`"+`(function(){var s=document.createElement('style');s.textContent=${JSON.stringify(css)};document.head.appendChild(s);})();`+"`"+` + '\n' + code
+      if (map) {
+        map.mappings = ';' + map.mappings
+      }
+    }
+
+    if (map) {
+      code += '\n//# sourceMappingURL=data:application/json;base64,' + Buffer.from(JSON.stringify(map)).toString('base64')
+    }
+
+    cachedBundle = code
+    lastBuildError = null
+  },
+}
+
+// 永続的な Rollup の watch ビルドを開始する
+// 変更されたモジュールだけを再変換し、結果をローダーに通知する
+async function startBundleWatcher(server) {
+  startBuilding()
+  let initialBuild = true
+
+  try {
+    const watcher: any = await build({
+      configFile: false,
+      logLevel: 'silent',
+      plugins: [%scaptureBundlePlugin],
+      define: {
+        'process.env.NODE_ENV': JSON.stringify('development'),
+      },
+      build: {
+        write: false,
+        watch: {},
+        minify: false,
+        sourcemap: true,
+        outDir: path.resolve(kcdevDir, 'dev-dist'),
+        emptyOutDir: false,
+        lib: {
+          entry: devEntry,
+          name: outputName,
+          formats: ['iife'],
+          fileName: () => outputName + '.js',
+        },
+        rollupOptions: {
+          output: {
+            assetFileNames: outputName + '.[ext]',
+            // DevTools にプロジェクトルートからのパスで元のソースを表示する
+            sourcemapPathTransform: (relative, mapPath) =>
+              path.relative(projectRoot, path.resolve(path.dirname(mapPath), relative)),
+          },
+        },
+      },
+    })
+
+    // 成功時はオーバーレイを消してフルリロード、失敗時はリロードせずにオーバーレイを更新する
+    watcher.on('event', (event) => {
+      switch (event.code) {
+        case 'START':
+          startBuilding()
+          break
+        case 'END':
+          endBuilding()
+          if (!initialBuild) {
+            server.ws.send({ type: 'custom', event: 'kcdev:build-ok' })
+            server.ws.send({ type: 'full-reload' })
+          }
+          initialBuild = false
+          break
+        case 'ERROR':
+          event.result?.close()
+          console.error('Build error:', event.error)
+          lastBuildError = toBuildError(event.error)
+          endBuilding()
+          server.ws.send({ type: 'custom', event: 'kcdev:build-error', data: lastBuildError })
+          initialBuild = false
+          break
+      }
+    })
+
+    server.httpServer?.on('close', () => watcher.close())
+  } catch (err) {
+    console.error('Build error:', err)
+    lastBuildError = toBuildError(err)
+    endBuilding()
+  }
+}
+
+// ESM モードでエントリーを HMR の境界にする
+// エントリーの再実行時はローダーが kintone のハンドラーを差し替え、直前のイベントで描画し直す
+const kcdevEntryHmrPlugin = {
+  name: 'kcdev-entry-hmr',
+  apply: 'serve',
+  transform(code, id) {
+    if (devMode !== 'esm' || normalizePath(id.split('?')[0]) !== normalizePath(devEntry)) {
+      return
+    }
+    return {
+      code: code + `+"`"+`
+if (import.meta.hot) {
+  import.meta.hot.accept()
+  import.meta.hot.dispose(() => window.__kcdev__?.dispose())
+  window.__kcdev__?.remount()
+}
+`+"`"+`,
+      map: null,
+    }
+  },
+}
 
 const kcdevPlugin = {
   name: 'kcdev',
   configureServer(server) {
-    // src/ ディレクトリの変更を検知してフルリロード
-    server.watcher.on('change', (file) => {
-      if (file.includes('/src/')) {
-        cachedBundle = null
-        server.ws.send({ type: 'full-reload' })
-      }
-    })
+    // 起動時にビルドを始めておき、最初のページ表示を待たせない
+    // ESM モードではローダーがモジュールを直接読み込むため、バンドルしない
+    if (devMode !== 'esm') {
+      startBundleWatcher(server)
+    }
 
-    // CORS/PNA ヘッダー
+    // CORS/PNA ヘッダー（kintone と開発サーバー自身のオリジンにだけ許可する）
     server.middlewares.use((req, res, next) => {
-      res.setHeader('Access-Control-Allow-Origin', '*')
-      res.setHeader('Access-Control-Allow-Methods', 'GET, OPTIONS')
-      res.setHeader('Access-Control-Allow-Headers', '*')
-      res.setHeader('Access-Control-Allow-Private-Network', 'true')
+      const origin = req.headers.origin
+      const allowed = !!origin && (allowedOrigins.includes(origin) || origin === devOrigin)
+      res.setHeader('Vary', 'Origin')
+      if (allowed) {
+        res.setHeader('Access-Control-Allow-Origin', origin)
+        res.setHeader('Access-Control-Allow-Methods', 'GET, OPTIONS')
+        res.setHeader('Access-Control-Allow-Headers', '*')
+        res.setHeader('Access-Control-Allow-Private-Network', 'true')
+      }
 
       if (req.method === 'OPTIONS') {
-        res.statusCode = 204
+        res.statusCode = allowed ? 204 : 403
         res.end()
         return
       }
       next()
     })
 
+    // セッショントークンを要求する場合は、Vite のミドルウェアより前にトークンの無い要求を拒否する
+    // Vite のモジュール・HMR はトークンを含む base（sessionBase）の下で配信し、kcdev のエンドポイントはクエリで確認する
+    server.middlewares.use((req, res, next) => {
+      const url = new URL(req.url ?? '/', devOrigin)
+      if (!sessionToken) {
+        // トークンを無効にした後も、以前のトークンを保存したローダーから読み込めるようにする
+        const stale = url.pathname.match(/^\/__kcdev\/s\/[^/]+(\/.*)$/)
+        if (stale) {
+          req.url = stale[1] + url.search
+        }
+        return next()
+      }
+
+      // プロジェクトルートの index.html は Vite が base（トークンを含む）にリダイレクトするため、開発マシンからだけ許可する
+      const isIndex = (url.pathname === '/' || url.pathname === '/index.html') && (!hasRootIndexHtml || isLocalRequest(req))
+      if (
+        url.pathname.startsWith(sessionBase) ||
+        url.pathname === '/__kcdev/status' ||
+        isIndex ||
+        hasSessionToken(req)
+      ) {
+        return next()
+      }
+      res.statusCode = 403
+      res.setHeader('Content-Type', 'application/json')
+      res.end(JSON.stringify(sessionTokenError()))
+    })
+
+    // /__kcdev/status - ローダーが読み込みに失敗した原因を確認する
+    server.middlewares.use('/__kcdev/status', (req, res) => {
+      res.setHeader('Content-Type', 'application/json')
+      res.setHeader('Cache-Control', 'no-store')
+      if (!hasSessionToken(req)) {
+        res.end(JSON.stringify({ ok: false, error: sessionTokenError() }))
+        return
+      }
+      res.end(JSON.stringify({ ok: lastBuildError === null, error: lastBuildError }))
+    })
+
+    // /__kcdev/log - ローダーが送るブラウザのログを kcdev（KCDEV_LOG_URL）に転送する
+    server.middlewares.use('/__kcdev/log', (req, res) => {
+      if (!isAllowedLogRequest(req)) {
+        req.resume()
+        res.statusCode = 403
+        res.end()
+        return
+      }
+
+      let body = ''
+      req.on('data', (chunk) => {
+        body += chunk
+      })
+      req.on('end', () => {
+        res.statusCode = 204
+        res.end()
+        const logUrl = process.env.KCDEV_LOG_URL
+        if (!logUrl) {
+          return
+        }
+        fetch(logUrl, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body,
+        }).catch(() => {})
+      })
+    })
+
     // プロジェクトルートにindex.htmlがない場合、.kcdev/index.htmlを返す
     if (!hasRootIndexHtml) {
       server.middlewares.use((req, res, next) => {
@@ -77,7 +367,12 @@ const kcdevPlugin = {
           const indexPath = path.join(kcdevDir, 'index.html')
           fs.readFile(indexPath, 'utf-8', (err, html) => {
             if (err) return next()
+            // kintone へのリダイレクトでローダーにセッショントークンを渡す（開発マシンで開いた場合だけ）
+            if (sessionToken && isLocalRequest(req)) {
+              html = html.replace('</head>', '<script>window.kcdevToken = ' + JSON.stringify(sessionToken) + '</script>\n</head>')
+            }
             res.setHeader('Content-Type', 'text/html')
+            res.setHeader('Cache-Control', 'no-store')
             res.end(html)
           })
           return
@@ -86,100 +381,86 @@ const kcdevPlugin = {
       })
     }
 
-    // /${outputName}.js - Vite でリアルタイムバンドル
+    // /${outputName}.js - watch ビルドの結果を返す（ビルド中は完了を待つ）
+    // 失敗時はローダーがオーバーレイを表示できるよう、エラーを JSON で返す
     server.middlewares.use(async (req, res, next) => {
       if (!req.url?.startsWith('/' + outputName + '.js')) {
         return next()
       }
 
-      try {
-        const result = await build({
-          configFile: false,
-          logLevel: 'silent',
-          plugins: [%s],
-          define: {
-            'process.env.NODE_ENV': JSON.stringify('development'),
-          },
-          build: {
-            write: false,
-            lib: {
-              entry: srcEntry,
-              name: outputName,
-              formats: ['iife'],
-              fileName: () => outputName + '.js',
-            },
-            rollupOptions: {
-              output: {
-                assetFileNames: outputName + '.[ext]',
-              },
-            },
-          },
-        })
-
-        const output = Array.isArray(result) ? result[0] : result
-        const jsChunk = output.output.find((o: any) => o.fileName === outputName + '.js')
-        const cssChunk = output.output.find((o: any) => o.fileName?.endsWith('.css'))
-
-        if (jsChunk && 'code' in jsChunk) {
-          let code = jsChunk.code
+      // 他のサイトが script 要素で読み込めないよう、セッショントークンの無いリクエストは拒否する
+      if (!hasSessionToken(req)) {
+        res.statusCode = 403
+        res.setHeader('Content-Type', 'application/json')
+        res.end(JSON.stringify(sessionTokenError()))
+        return
+      }
 
-          // CSS をインライン化
-          if (cssChunk && 'source' in cssChunk) {
-            const cssCode = ` + "`" + `(function(){var s=document.createElement('style');s.textContent=${JSON.stringify(cssChunk.source)};document.head.appendChild(s);})();` + "`" + `
-            code = cssCode + code
-          }
+      if (building) {
+        await building
+      }
 
-          cachedBundle = code
-          res.setHeader('Content-Type', 'application/javascript')
-          res.end(code)
-        } else {
-          throw new Error('Build output not found')
-        }
-      } catch (err) {
-        console.error('Build error:', err)
-        res.statusCode = 500
-        res.end(` + "`" + `console.error(${JSON.stringify(String(err))})` + "`" + `)
+      if (cachedBundle && !lastBuildError) {
+        res.setHeader('Content-Type', 'application/javascript')
+        res.setHeader('Cache-Control', 'no-store')
+        res.end(cachedBundle)
+        return
       }
+
+      res.statusCode = 500
+      res.setHeader('Content-Type', 'application/json')
+      res.end(JSON.stringify(lastBuildError ?? toBuildError(new Error('Build output not found'))))
     })
   },
 }
 
-export default defineConfig({
-  root: projectRoot,
-  plugins: [%skcdevPlugin],
-  server: {
-    https: {
-      key: fs.readFileSync(path.join(certDir, 'localhost-key.pem')),
-      cert: fs.readFileSync(path.join(certDir, 'localhost.pem')),
-    },
-    port: 3000,
-    strictPort: true,
-    origin: 'https://localhost:3000',
-  },
-  define: {
-    'process.env.NODE_ENV': JSON.stringify('production'),
-  },
-  build: {
-    lib: {
-      entry: srcEntry,
-      name: outputName,
-      formats: ['iife'],
-      fileName: () => outputName + '.js',
+export default defineConfig(({ command }) => {
+  // 本番ビルドだけ production にして console 出力を取り除く
+  // 開発サーバー（ESM モード）は同じ設定でソースを変換するため、開発版のまま console 出力も残す
+  const isBuild = command === 'build'
+
+  return {
+    root: projectRoot,
+    plugins: [%skcdevEntryHmrPlugin, kcdevPlugin],
+    // kcdev dev でセッショントークンを要求する場合だけ、モジュールの URL にトークンを含める
+    base: sessionBase,
+    server: {
+      https: {
+        key: fs.readFileSync(path.join(certDir, 'localhost-key.pem')),
+        cert: fs.readFileSync(path.join(certDir, 'localhost.pem')),
+      },
+      host: devHost,
+      port: devPort,
+      strictPort: true,
+      origin: devOrigin,
+      // CORS は kcdevPlugin で kintone のオリジンにだけ許可する
+      cors: false,
     },
-    outDir: path.resolve(__dirname, '../dist'),
-    emptyOutDir: true,
-    rollupOptions: {
-      output: {
-        assetFileNames: outputName + '.[ext]',
+    define: isBuild ? {
+      'process.env.NODE_ENV': JSON.stringify('production'),
+    } : {},
+    build: {
+      lib: {
+        entry: srcEntry,
+        name: outputName,
+        formats: ['iife'],
+        fileName: () => outputName + '.js',
+      },
+      outDir: path.resolve(__dirname, '../dist'),
+      emptyOutDir: true,
+      rollupOptions: {
+        output: {
+          assetFileNames: outputName + '.[ext]',
+        },
       },
     },
-  },
-  esbuild: {
-    drop: ['debugger'],
-    pure: ['console.log', 'console.info', 'console.debug', 'console.warn', 'console.trace'],
-  },
+    esbuild: isBuild ? {
+      drop: ['debugger'],
+      pure: ['console.log', 'console.info', 'console.debug', 'console.warn', 'console.trace'],
+    } : {},
+  }
 })
-`, imports, entry, getBuildPlugins(framework), plugins)
+`, imports, entry, plugins, plugins)
 }
 
 func getViteImports(framework prompt.Framework) string {
@@ -207,16 +488,3 @@ func getVitePlugins(framework prompt.Framework) string {
 		return ""
 	}
 }
-
-func getBuildPlugins(framework prompt.Framework) string {
-	switch framework {
-	case prompt.FrameworkReact:
-		return "react()"
-	case prompt.FrameworkVue:
-		return "vue()"
-	case prompt.FrameworkSvelte:
-		return "svelte()"
-	default:
-		return ""
-	}
-}
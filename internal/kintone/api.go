@@ -2,49 +2,198 @@ package kintone
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 )
 
 type Client struct {
-	domain   string
-	username string
-	password string
-	client   *http.Client
+	domain       string
+	guestSpaceID int
+	auth         Auth
+	basicAuth    string
+	retry        RetryPolicy
+	client       *http.Client
 }
 
-func NewClient(domain, username, password string) *Client {
-	return &Client{
-		domain:   domain,
-		username: username,
-		password: password,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// Auth は kintone API の認証情報を表す
+// APIToken が設定されている場合はパスワード認証より優先される
+type Auth struct {
+	Username string
+	Password string
+	// APIToken は複数のトークンをカンマ区切りで指定できる
+	APIToken string
+}
+
+// UsesAPIToken は API トークン認証を使用するかを返す
+func (a Auth) UsesAPIToken() bool {
+	return a.APIToken != ""
+}
+
+// IsEmpty は認証情報が未設定かどうかを返す
+func (a Auth) IsEmpty() bool {
+	return a.APIToken == "" && (a.Username == "" || a.Password == "")
+}
+
+func NewClient(opts ClientOptions) (*Client, error) {
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
 	}
+
+	domain := opts.Domain
+	if opts.ClientCert != nil {
+		domain = SecureAccessDomain(domain)
+	}
+
+	retry := DefaultRetryPolicy
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
+
+	return &Client{
+		domain:       domain,
+		guestSpaceID: opts.GuestSpaceID,
+		auth:         opts.Auth,
+		basicAuth:    basicAuthHeader(opts.BasicAuth),
+		retry:        retry,
+		client:       httpClient,
+	}, nil
 }
 
 func (c *Client) baseURL() string {
 	return fmt.Sprintf("https://%s", c.domain)
 }
 
-func (c *Client) authHeader() string {
-	auth := base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))
-	return auth
+// apiURL は REST API のエンドポイント URL を返す
+// ゲストスペースのアプリは /k/guest/{spaceId}/v1/... を使用する
+func (c *Client) apiURL(path string) string {
+	if c.guestSpaceID > 0 {
+		return fmt.Sprintf("%s/k/guest/%d/v1%s", c.baseURL(), c.guestSpaceID, path)
+	}
+	return c.baseURL() + "/k/v1" + path
+}
+
+// setAuthHeader は認証方式に応じたヘッダーを設定する
+// kintone は両方のヘッダーがあるとパスワード認証を優先するため、どちらか一方のみ送る
+func (c *Client) setAuthHeader(req *http.Request) {
+	if c.basicAuth != "" {
+		req.Header.Set("Authorization", c.basicAuth)
+	}
+	if c.auth.UsesAPIToken() {
+		req.Header.Set("X-Cybozu-API-Token", c.auth.APIToken)
+		return
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(c.auth.Username + ":" + c.auth.Password))
+	req.Header.Set("X-Cybozu-Authorization", auth)
+}
+
+// apiRequest は 1 回の API 呼び出しの内容
+// リトライ時に再送できるよう、ボディはバイト列で保持する
+type apiRequest struct {
+	operation   string
+	method      string
+	url         string
+	body        []byte
+	contentType string
+	// idempotent が false の操作は、サーバーが処理したか判断できないエラーではリトライしない
+	idempotent bool
+	// noRetry は do でリトライしない（呼び出し元が結果を確認してからリトライする場合）
+	noRetry bool
+}
+
+// do はリクエストを送信し、成功時は out にレスポンスをデコードする
+// 一時的なエラー（429、5xx、GAIA_TM12、接続エラー）はリトライポリシーに従って再試行する
+func (c *Client) do(ctx context.Context, r apiRequest, out interface{}) error {
+	maxRetries := c.retry.MaxRetries
+	if r.noRetry {
+		maxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if r.body != nil {
+			body = bytes.NewReader(r.body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, r.url, body)
+		if err != nil {
+			return err
+		}
+
+		c.setAuthHeader(req)
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			// キャンセルされた場合はリトライせず、そのまま呼び出し元に返す
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if attempt < maxRetries && retryableNetError(err, r.idempotent) {
+				if err := sleepContext(ctx, c.retry.delay(attempt, nil)); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("%sエラー: %w", r.operation, err)
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if attempt < maxRetries && retryableNetError(err, r.idempotent) {
+				if err := sleepContext(ctx, c.retry.delay(attempt, nil)); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("%sエラー: %w", r.operation, err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			kerr := parseKintoneError(r.operation, resp.StatusCode, respBody)
+			if attempt < maxRetries && kerr.retryable(r.idempotent) {
+				if err := sleepContext(ctx, c.retry.delay(attempt, resp)); err != nil {
+					return err
+				}
+				continue
+			}
+			return kerr
+		}
+
+		if out == nil {
+			return nil
+		}
+		// ファイルのダウンロードなど JSON 以外のレスポンスはそのまま返す
+		if raw, ok := out.(*[]byte); ok {
+			*raw = respBody
+			return nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("%sエラー: レスポンスの解析に失敗しました: %w", r.operation, err)
+		}
+		return nil
+	}
 }
 
 type FileUploadResponse struct {
 	FileKey string `json:"fileKey"`
 }
 
-func (c *Client) UploadFile(filePath string) (string, error) {
+func (c *Client) UploadFile(ctx context.Context, filePath string) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
@@ -67,39 +216,44 @@ func (c *Client) UploadFile(filePath string) (string, error) {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL()+"/k/v1/file.json", body)
+	// 同じファイルを再アップロードしても一時ファイルが増えるだけなのでリトライしてよい
+	var result FileUploadResponse
+	err = c.do(ctx, apiRequest{
+		operation:   "ファイルアップロード",
+		method:      "POST",
+		url:         c.apiURL("/file.json"),
+		body:        body.Bytes(),
+		contentType: writer.FormDataContentType(),
+		idempotent:  true,
+	}, &result)
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("X-Cybozu-Authorization", c.authHeader())
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return result.FileKey, nil
+}
 
-	resp, err := c.client.Do(req)
+// DownloadFile はアップロード済みファイルの内容を取得する
+func (c *Client) DownloadFile(ctx context.Context, fileKey string) ([]byte, error) {
+	var data []byte
+	err := c.do(ctx, apiRequest{
+		operation:  "ファイルダウンロード",
+		method:     "GET",
+		url:        c.apiURL("/file.json") + "?fileKey=" + url.QueryEscape(fileKey),
+		idempotent: true,
+	}, &data)
 	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ファイルアップロードエラー: %s - %s", resp.Status, string(respBody))
-	}
-
-	var result FileUploadResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return nil, err
 	}
-
-	return result.FileKey, nil
+	return data, nil
 }
 
 type CustomizeScope string
 
 const (
-	ScopeAll    CustomizeScope = "ALL"
-	ScopeAdmin  CustomizeScope = "ADMIN"
-	ScopeNone   CustomizeScope = "NONE"
+	ScopeAll   CustomizeScope = "ALL"
+	ScopeAdmin CustomizeScope = "ADMIN"
+	ScopeNone  CustomizeScope = "NONE"
 )
 
 type FileCustomization struct {
@@ -116,7 +270,7 @@ type File struct {
 
 // GetCustomize は現在のカスタマイズ設定を取得する
 type CustomizeResponse struct {
-	Scope   CustomizeScope                 `json:"scope"`
+	Scope   CustomizeScope                  `json:"scope"`
 	Desktop *CustomizeDesktopMobileResponse `json:"desktop"`
 	Mobile  *CustomizeDesktopMobileResponse `json:"mobile"`
 }
@@ -127,40 +281,28 @@ type CustomizeDesktopMobileResponse struct {
 }
 
 type FileCustomizationResponse struct {
-	Type string       `json:"type"`
+	Type string        `json:"type"`
 	File *FileResponse `json:"file,omitempty"`
-	URL  string       `json:"url,omitempty"`
+	URL  string        `json:"url,omitempty"`
 }
 
 type FileResponse struct {
-	FileKey   string `json:"fileKey"`
-	Name      string `json:"name"`
-	Size      string `json:"size"`
+	FileKey     string `json:"fileKey"`
+	Name        string `json:"name"`
+	Size        string `json:"size"`
 	ContentType string `json:"contentType,omitempty"`
 }
 
 // GetCustomize は現在のカスタマイズ設定を取得する
-func (c *Client) GetCustomize(appID int) (*CustomizeResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/k/v1/app/customize.json?app=%d", c.baseURL(), appID), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("X-Cybozu-Authorization", c.authHeader())
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("カスタマイズ取得エラー: %s - %s", resp.Status, string(respBody))
-	}
-
+func (c *Client) GetCustomize(ctx context.Context, appID int) (*CustomizeResponse, error) {
 	var result CustomizeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	err := c.do(ctx, apiRequest{
+		operation:  "カスタマイズ取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL("/app/customize.json"), appID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
 		return nil, err
 	}
 
@@ -179,58 +321,37 @@ type ExistingFiles struct {
 }
 
 // GetExistingCustomizations は kcdev 管理外のカスタマイズを取得する
-func (c *Client) GetExistingCustomizations(appID int, kcdevFiles []string) (*ExistingCustomizations, error) {
-	customize, err := c.GetCustomize(appID)
+// kcdevFiles には kcdev が管理するファイル名または URL を指定する
+func (c *Client) GetExistingCustomizations(ctx context.Context, appID int, kcdevFiles []string) (*ExistingCustomizations, error) {
+	customize, err := c.GetCustomize(ctx, appID)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &ExistingCustomizations{}
 
-	// kcdev 管理ファイルかどうかをチェック
-	isKcdevFile := func(name string) bool {
-		for _, f := range kcdevFiles {
-			if name == f {
-				return true
+	collect := func(list []FileCustomizationResponse) []string {
+		var names []string
+		for _, f := range list {
+			if isManagedEntry(f, kcdevFiles) {
+				continue
+			}
+			if f.Type == "FILE" && f.File != nil {
+				names = append(names, f.File.Name)
+			} else if f.Type == "URL" && f.URL != "" {
+				names = append(names, f.URL)
 			}
 		}
-		return false
+		return names
 	}
 
-	// Desktop
 	if customize.Desktop != nil {
-		for _, js := range customize.Desktop.JS {
-			if js.Type == "FILE" && js.File != nil && !isKcdevFile(js.File.Name) {
-				result.Desktop.JS = append(result.Desktop.JS, js.File.Name)
-			} else if js.Type == "URL" && js.URL != "" {
-				result.Desktop.JS = append(result.Desktop.JS, js.URL)
-			}
-		}
-		for _, css := range customize.Desktop.CSS {
-			if css.Type == "FILE" && css.File != nil && !isKcdevFile(css.File.Name) {
-				result.Desktop.CSS = append(result.Desktop.CSS, css.File.Name)
-			} else if css.Type == "URL" && css.URL != "" {
-				result.Desktop.CSS = append(result.Desktop.CSS, css.URL)
-			}
-		}
+		result.Desktop.JS = collect(customize.Desktop.JS)
+		result.Desktop.CSS = collect(customize.Desktop.CSS)
 	}
-
-	// Mobile
 	if customize.Mobile != nil {
-		for _, js := range customize.Mobile.JS {
-			if js.Type == "FILE" && js.File != nil && !isKcdevFile(js.File.Name) {
-				result.Mobile.JS = append(result.Mobile.JS, js.File.Name)
-			} else if js.Type == "URL" && js.URL != "" {
-				result.Mobile.JS = append(result.Mobile.JS, js.URL)
-			}
-		}
-		for _, css := range customize.Mobile.CSS {
-			if css.Type == "FILE" && css.File != nil && !isKcdevFile(css.File.Name) {
-				result.Mobile.CSS = append(result.Mobile.CSS, css.File.Name)
-			} else if css.Type == "URL" && css.URL != "" {
-				result.Mobile.CSS = append(result.Mobile.CSS, css.URL)
-			}
-		}
+		result.Mobile.JS = collect(customize.Mobile.JS)
+		result.Mobile.CSS = collect(customize.Mobile.CSS)
 	}
 
 	return result, nil
@@ -264,10 +385,10 @@ func (e *ExistingCustomizations) Summary() string {
 }
 
 type CustomizeRequest struct {
-	App     int                          `json:"app"`
-	Scope   CustomizeScope               `json:"scope"`
-	Desktop *CustomizeDesktopMobile      `json:"desktop,omitempty"`
-	Mobile  *CustomizeDesktopMobile      `json:"mobile,omitempty"`
+	App     int                     `json:"app"`
+	Scope   CustomizeScope          `json:"scope"`
+	Desktop *CustomizeDesktopMobile `json:"desktop,omitempty"`
+	Mobile  *CustomizeDesktopMobile `json:"mobile,omitempty"`
 }
 
 type CustomizeDesktopMobile struct {
@@ -278,86 +399,62 @@ type CustomizeDesktopMobile struct {
 type CustomizeFiles struct {
 	JSFileKey  string
 	CSSFileKey string
+
+	// 以下は kcdev のファイルと一緒に登録する外部ライブラリ
+	// Before は kcdev のファイルより前、After は後ろに記載順で並ぶ
+	JSBefore  []FileCustomization
+	JSAfter   []FileCustomization
+	CSSBefore []FileCustomization
+	CSSAfter  []FileCustomization
 }
 
-func (c *Client) UpdateCustomize(appID int, desktopFiles, mobileFiles *CustomizeFiles, scope CustomizeScope) error {
-	customize := CustomizeRequest{
-		App:   appID,
-		Scope: scope,
+// jsList は登録順に並べた JS の一覧を返す
+func (f *CustomizeFiles) jsList() []FileCustomization {
+	if f == nil {
+		return []FileCustomization{}
 	}
+	return joinEntries(f.JSBefore, f.JSFileKey, f.JSAfter)
+}
 
-	// Desktop
-	if desktopFiles != nil {
-		jsFiles := []FileCustomization{}
-		cssFiles := []FileCustomization{}
-
-		if desktopFiles.JSFileKey != "" {
-			jsFiles = []FileCustomization{
-				{
-					Type: "FILE",
-					File: &File{FileKey: desktopFiles.JSFileKey},
-				},
-			}
-		}
-
-		if desktopFiles.CSSFileKey != "" {
-			cssFiles = []FileCustomization{
-				{
-					Type: "FILE",
-					File: &File{FileKey: desktopFiles.CSSFileKey},
-				},
-			}
-		}
-
-		customize.Desktop = &CustomizeDesktopMobile{
-			JS:  jsFiles,
-			CSS: cssFiles,
-		}
-	} else {
-		customize.Desktop = &CustomizeDesktopMobile{
-			JS:  []FileCustomization{},
-			CSS: []FileCustomization{},
-		}
+// cssList は登録順に並べた CSS の一覧を返す
+func (f *CustomizeFiles) cssList() []FileCustomization {
+	if f == nil {
+		return []FileCustomization{}
 	}
+	return joinEntries(f.CSSBefore, f.CSSFileKey, f.CSSAfter)
+}
 
-	// Mobile
-	if mobileFiles != nil {
-		jsFiles := []FileCustomization{}
-		cssFiles := []FileCustomization{}
-
-		if mobileFiles.JSFileKey != "" {
-			jsFiles = []FileCustomization{
-				{
-					Type: "FILE",
-					File: &File{FileKey: mobileFiles.JSFileKey},
-				},
-			}
-		}
-
-		if mobileFiles.CSSFileKey != "" {
-			cssFiles = []FileCustomization{
-				{
-					Type: "FILE",
-					File: &File{FileKey: mobileFiles.CSSFileKey},
-				},
-			}
-		}
+func joinEntries(before []FileCustomization, fileKey string, after []FileCustomization) []FileCustomization {
+	list := []FileCustomization{}
+	list = append(list, before...)
+	if fileKey != "" {
+		list = append(list, FileCustomization{
+			Type: "FILE",
+			File: &File{FileKey: fileKey},
+		})
+	}
+	return append(list, after...)
+}
 
-		customize.Mobile = &CustomizeDesktopMobile{
-			JS:  jsFiles,
-			CSS: cssFiles,
-		}
-	} else {
-		customize.Mobile = &CustomizeDesktopMobile{
-			JS:  []FileCustomization{},
-			CSS: []FileCustomization{},
-		}
+func (c *Client) UpdateCustomize(ctx context.Context, appID int, desktopFiles, mobileFiles *CustomizeFiles, scope CustomizeScope) error {
+	customize := CustomizeRequest{
+		App:   appID,
+		Scope: scope,
+		// 対象外のターゲットは空のリストで登録を解除する
+		Desktop: &CustomizeDesktopMobile{
+			JS:  desktopFiles.jsList(),
+			CSS: desktopFiles.cssList(),
+		},
+		Mobile: &CustomizeDesktopMobile{
+			JS:  mobileFiles.jsList(),
+			CSS: mobileFiles.cssList(),
+		},
 	}
 
-	return c.updateCustomizeRequest(customize)
+	return c.updateCustomizeRequest(ctx, customize)
 }
 
-func (c *Client) UpdateCustomizeWithURL(appID int, jsURLs []string, targetDesktop, targetMobile bool) error {
+func (c *Client) UpdateCustomizeWithURL(ctx context.Context, appID int, jsURLs []string, targetDesktop, targetMobile bool) error {
 	js := make([]FileCustomization, len(jsURLs))
 	for i, url := range jsURLs {
 		js[i] = FileCustomization{
@@ -395,36 +492,83 @@ func (c *Client) UpdateCustomizeWithURL(appID int, jsURLs []string, targetDeskto
 		}
 	}
 
-	return c.updateCustomizeRequest(customize)
+	return c.updateCustomizeRequest(ctx, customize)
 }
 
-func (c *Client) updateCustomizeRequest(customize CustomizeRequest) error {
+// RestoreCustomize は GetCustomize で取得したカスタマイズ設定を、順序と適用範囲を含めてそのまま登録する
+// fileKeys には取得時の fileKey から再アップロード後の fileKey への対応を指定する
+func (c *Client) RestoreCustomize(ctx context.Context, appID int, saved *CustomizeResponse, fileKeys map[string]string) error {
+	convert := func(list []FileCustomizationResponse) ([]FileCustomization, error) {
+		result := []FileCustomization{}
+		for _, f := range list {
+			if f.Type != "FILE" {
+				result = append(result, FileCustomization{Type: f.Type, URL: f.URL})
+				continue
+			}
+			if f.File == nil {
+				continue
+			}
+			key, ok := fileKeys[f.File.FileKey]
+			if !ok {
+				return nil, fmt.Errorf("%s の再アップロード結果が見つかりません", f.File.Name)
+			}
+			result = append(result, FileCustomization{Type: "FILE", File: &File{FileKey: key}})
+		}
+		return result, nil
+	}
 
-	body, err := json.Marshal(customize)
+	convertSet := func(set *CustomizeDesktopMobileResponse) (*CustomizeDesktopMobile, error) {
+		if set == nil {
+			set = &CustomizeDesktopMobileResponse{}
+		}
+		js, err := convert(set.JS)
+		if err != nil {
+			return nil, err
+		}
+		css, err := convert(set.CSS)
+		if err != nil {
+			return nil, err
+		}
+		return &CustomizeDesktopMobile{JS: js, CSS: css}, nil
+	}
+
+	desktop, err := convertSet(saved.Desktop)
 	if err != nil {
 		return err
 	}
-
-	req, err := http.NewRequest("PUT", c.baseURL()+"/k/v1/preview/app/customize.json", bytes.NewReader(body))
+	mobile, err := convertSet(saved.Mobile)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("X-Cybozu-Authorization", c.authHeader())
-	req.Header.Set("Content-Type", "application/json")
+	scope := saved.Scope
+	if scope == "" {
+		scope = ScopeAll
+	}
+
+	return c.updateCustomizeRequest(ctx, CustomizeRequest{
+		App:     appID,
+		Scope:   scope,
+		Desktop: desktop,
+		Mobile:  mobile,
+	})
+}
 
-	resp, err := c.client.Do(req)
+func (c *Client) updateCustomizeRequest(ctx context.Context, customize CustomizeRequest) error {
+	body, err := json.Marshal(customize)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("カスタマイズ更新エラー: %s - %s", resp.Status, string(respBody))
-	}
-
-	return nil
+	// PUT は同じ内容で上書きするだけなので何度送っても結果は変わらない
+	return c.do(ctx, apiRequest{
+		operation:   "カスタマイズ更新",
+		method:      "PUT",
+		url:         c.apiURL("/preview/app/customize.json"),
+		body:        body,
+		contentType: "application/json",
+		idempotent:  true,
+	}, nil)
 }
 
 type DeployRequest struct {
@@ -435,7 +579,7 @@ type DeployApp struct {
 	App int `json:"app"`
 }
 
-func (c *Client) DeployApp(appID int) error {
+func (c *Client) DeployApp(ctx context.Context, appID int) error {
 	deployReq := DeployRequest{
 		Apps: []DeployApp{{App: appID}},
 	}
@@ -445,26 +589,112 @@ func (c *Client) DeployApp(appID int) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL()+"/k/v1/preview/app/deploy.json", bytes.NewReader(body))
-	if err != nil {
-		return err
+	// デプロイの POST は do ではリトライせず、再送・状態の確認をこのループの 1 つのリトライ回数で判断する
+	req := apiRequest{
+		operation:   "デプロイ開始",
+		method:      "POST",
+		url:         c.apiURL("/preview/app/deploy.json"),
+		body:        body,
+		contentType: "application/json",
+		noRetry:     true,
 	}
 
-	req.Header.Set("X-Cybozu-Authorization", c.authHeader())
-	req.Header.Set("Content-Type", "application/json")
+	// unsure は送信後に接続が切れ、デプロイが開始されたか分からないときのエラー
+	var unsure error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retry.delay(attempt-1, nil)); err != nil {
+				return err
+			}
+		}
+
+		// 開始されたか分からない間は再送せず、デプロイの状態を確認する
+		if unsure != nil {
+			started, err := c.deployStarted(ctx, appID)
+			switch {
+			case err == nil && started:
+				return nil
+			case err == nil:
+				// デプロイは開始されていないため、下で再送する
+				unsure = nil
+			case ctx.Err() != nil:
+				return ctx.Err()
+			case attempt >= c.retry.MaxRetries:
+				return unsure
+			default:
+				continue
+			}
+		}
+
+		err := c.do(ctx, req, nil)
+		if err == nil {
+			return nil
+		}
+		var kerr *KintoneError
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &kerr):
+			// サーバーが処理していないことが明らかなエラー（429、503、GAIA_TM12）だけ再送する
+			if !kerr.retryable(false) {
+				return err
+			}
+		case !retryableNetError(err, false):
+			// 接続前の失敗以外は、次の回でデプロイの状態を確認する
+			unsure = err
+		}
+		if attempt >= c.retry.MaxRetries {
+			return err
+		}
+	}
+}
 
-	resp, err := c.client.Do(req)
+// deployStarted は送信後に接続が切れたデプロイが開始されたかを返す（リトライしない）
+// 処理中・失敗・キャンセルは開始されたとみなし、結果は WaitForDeploy で報告する
+// 成功は以前のデプロイの結果の場合があるため、本番環境にプレビュー環境の設定が反映されているかで判断する
+func (c *Client) deployStarted(ctx context.Context, appID int) (bool, error) {
+	status, err := c.deployStatus(ctx, appID, true)
 	if err != nil {
-		return err
+		return false, err
+	}
+	if status != "SUCCESS" {
+		return true, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("デプロイ開始エラー: %s - %s", resp.Status, string(respBody))
+	live, err := c.appRevision(ctx, appID, false)
+	if err != nil {
+		return false, err
+	}
+	preview, err := c.appRevision(ctx, appID, true)
+	if err != nil {
+		return false, err
 	}
+	return live == preview, nil
+}
+
+type appSettingsResponse struct {
+	Revision string `json:"revision"`
+}
 
-	return nil
+// appRevision は本番環境（preview が true の場合はプレビュー環境）のアプリの設定のリビジョンを返す（リトライしない）
+// デプロイが完了すると、本番環境のリビジョンはプレビュー環境と同じになる
+func (c *Client) appRevision(ctx context.Context, appID int, preview bool) (string, error) {
+	path := "/app/settings.json"
+	if preview {
+		path = "/preview/app/settings.json"
+	}
+	var result appSettingsResponse
+	err := c.do(ctx, apiRequest{
+		operation:  "アプリの設定取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL(path), appID),
+		idempotent: true,
+		noRetry:    true,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Revision, nil
 }
 
 type DeployStatusResponse struct {
@@ -476,36 +706,45 @@ type DeployStatusApp struct {
 	Status string `json:"status"`
 }
 
-func (c *Client) WaitForDeploy(appID int) error {
-	for i := 0; i < 60; i++ {
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/k/v1/preview/app/deploy.json?apps=%d", c.baseURL(), appID), nil)
-		if err != nil {
-			return err
-		}
-
-		req.Header.Set("X-Cybozu-Authorization", c.authHeader())
+// deployStatus はアプリのデプロイ状態（PROCESSING / SUCCESS / FAIL / CANCEL）を返す
+// noRetry が true の場合は、一時的なエラーでもリトライしない
+func (c *Client) deployStatus(ctx context.Context, appID int, noRetry bool) (string, error) {
+	var status DeployStatusResponse
+	err := c.do(ctx, apiRequest{
+		operation:  "デプロイ状態取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?apps=%d", c.apiURL("/preview/app/deploy.json"), appID),
+		idempotent: true,
+		noRetry:    noRetry,
+	}, &status)
+	if err != nil {
+		return "", err
+	}
+	if len(status.Apps) == 0 {
+		return "", fmt.Errorf("デプロイ状態が取得できませんでした")
+	}
+	return status.Apps[0].Status, nil
+}
 
-		resp, err := c.client.Do(req)
+func (c *Client) WaitForDeploy(ctx context.Context, appID int) error {
+	for i := 0; i < 60; i++ {
+		status, err := c.deployStatus(ctx, appID, false)
 		if err != nil {
 			return err
 		}
 
-		var status DeployStatusResponse
-		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-			resp.Body.Close()
-			return err
-		}
-		resp.Body.Close()
-
-		if len(status.Apps) > 0 && status.Apps[0].Status == "SUCCESS" {
+		switch status {
+		case "SUCCESS":
 			return nil
-		}
-
-		if len(status.Apps) > 0 && status.Apps[0].Status == "FAIL" {
+		case "FAIL":
 			return fmt.Errorf("デプロイ失敗")
+		case "CANCEL":
+			return fmt.Errorf("デプロイがキャンセルされました")
 		}
 
-		time.Sleep(1 * time.Second)
+		if err := sleepContext(ctx, 1*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return fmt.Errorf("デプロイタイムアウト")
@@ -0,0 +1,153 @@
+package kintone
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient は srv に接続するクライアントを返す（リトライの待ち時間は短くする）
+func newTestClient(srv *httptest.Server, maxRetries int) *Client {
+	return &Client{
+		domain: strings.TrimPrefix(srv.URL, "https://"),
+		retry:  RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		client: srv.Client(),
+	}
+}
+
+// dropConnection はレスポンスを返さずに接続を切る（送信後の切断）
+func dropConnection(w http.ResponseWriter) {
+	conn, _, _ := w.(http.Hijacker).Hijack()
+	conn.Close()
+}
+
+func TestDeployApp(t *testing.T) {
+	tests := []struct {
+		name string
+		// post は n 回目（1 始まり）のデプロイの POST に応答する
+		post func(w http.ResponseWriter, n int32)
+		// status はデプロイ状態の取得への応答（live / preview はアプリの設定のリビジョン）
+		status          string
+		live, preview   string
+		maxRetries      int
+		wantErr         bool
+		wantPosts       int32
+		wantStatusCalls bool
+	}{
+		{
+			name:      "成功",
+			post:      func(w http.ResponseWriter, n int32) { w.Write([]byte("{}")) },
+			wantPosts: 1,
+		},
+		{
+			name: "503 は再送する",
+			post: func(w http.ResponseWriter, n int32) {
+				if n == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte(`{"code":"GAIA_MA01","message":"メンテナンス中"}`))
+					return
+				}
+				w.Write([]byte("{}"))
+			},
+			maxRetries: 3,
+			wantPosts:  2,
+		},
+		{
+			name: "500 は処理されたか分からないため再送しない",
+			post: func(w http.ResponseWriter, n int32) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"code":"CB_IJ01","message":"error"}`))
+			},
+			maxRetries: 3,
+			wantErr:    true,
+			wantPosts:  1,
+		},
+		{
+			name:            "送信後の切断で処理中なら再送しない",
+			post:            func(w http.ResponseWriter, n int32) { dropConnection(w) },
+			status:          "PROCESSING",
+			maxRetries:      3,
+			wantPosts:       1,
+			wantStatusCalls: true,
+		},
+		{
+			name:            "送信後の切断で直前のデプロイが成功していれば再送しない",
+			post:            func(w http.ResponseWriter, n int32) { dropConnection(w) },
+			status:          "SUCCESS",
+			live:            "5",
+			preview:         "5",
+			maxRetries:      3,
+			wantPosts:       1,
+			wantStatusCalls: true,
+		},
+		{
+			name:            "送信後の切断で失敗していれば再送しない",
+			post:            func(w http.ResponseWriter, n int32) { dropConnection(w) },
+			status:          "FAIL",
+			maxRetries:      3,
+			wantPosts:       1,
+			wantStatusCalls: true,
+		},
+		{
+			name: "送信後の切断で以前の成功だけなら再送する",
+			post: func(w http.ResponseWriter, n int32) {
+				if n == 1 {
+					dropConnection(w)
+					return
+				}
+				w.Write([]byte("{}"))
+			},
+			status:          "SUCCESS",
+			live:            "4",
+			preview:         "5",
+			maxRetries:      3,
+			wantPosts:       2,
+			wantStatusCalls: true,
+		},
+		{
+			name:       "リトライしない設定では切断をそのまま返す",
+			post:       func(w http.ResponseWriter, n int32) { dropConnection(w) },
+			status:     "PROCESSING",
+			maxRetries: 0,
+			wantErr:    true,
+			wantPosts:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts, statusCalls atomic.Int32
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/k/v1/preview/app/deploy.json" && r.Method == http.MethodPost:
+					tt.post(w, posts.Add(1))
+				case r.URL.Path == "/k/v1/preview/app/deploy.json":
+					statusCalls.Add(1)
+					w.Write([]byte(`{"apps":[{"app":"1","status":"` + tt.status + `"}]}`))
+				case r.URL.Path == "/k/v1/app/settings.json":
+					w.Write([]byte(`{"revision":"` + tt.live + `"}`))
+				case r.URL.Path == "/k/v1/preview/app/settings.json":
+					w.Write([]byte(`{"revision":"` + tt.preview + `"}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			err := newTestClient(srv, tt.maxRetries).DeployApp(context.Background(), 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeployApp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := posts.Load(); got != tt.wantPosts {
+				t.Errorf("POST の回数 = %d, want %d", got, tt.wantPosts)
+			}
+			if got := statusCalls.Load() > 0; got != tt.wantStatusCalls {
+				t.Errorf("デプロイ状態の確認 = %v, want %v", got, tt.wantStatusCalls)
+			}
+		})
+	}
+}
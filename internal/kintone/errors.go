@@ -0,0 +1,124 @@
+package kintone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// KintoneError は kintone REST API のエラーレスポンスを表す
+type KintoneError struct {
+	// Operation はエラーが発生した操作名（例: "カスタマイズ更新"）
+	Operation  string                `json:"-"`
+	StatusCode int                   `json:"-"`
+	Code       string                `json:"code"`
+	ID         string                `json:"id"`
+	Message    string                `json:"message"`
+	Errors     map[string]FieldError `json:"errors,omitempty"`
+}
+
+// FieldError はパラメータごとのエラー詳細
+type FieldError struct {
+	Messages []string `json:"messages"`
+}
+
+// parseKintoneError はレスポンスボディから KintoneError を生成する
+// JSON でない場合（Basic 認証のエラーページなど）はボディの先頭をメッセージとして扱う
+func parseKintoneError(operation string, statusCode int, body []byte) *KintoneError {
+	e := &KintoneError{Operation: operation, StatusCode: statusCode}
+	if err := json.Unmarshal(body, e); err != nil || (e.Code == "" && e.Message == "") {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		if msg == "" {
+			msg = http.StatusText(statusCode)
+		}
+		e.Message = msg
+	}
+	return e
+}
+
+func (e *KintoneError) Error() string {
+	var b strings.Builder
+	if e.Operation != "" {
+		b.WriteString(e.Operation + "エラー: ")
+	}
+	if e.Code != "" {
+		fmt.Fprintf(&b, "[%s] ", e.Code)
+	}
+	b.WriteString(e.Message)
+	if e.ID != "" {
+		fmt.Fprintf(&b, " (id: %s)", e.ID)
+	}
+
+	// パラメータごとのエラーは順序を固定して表示
+	keys := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n  %s: %s", k, strings.Join(e.Errors[k].Messages, ", "))
+	}
+	return b.String()
+}
+
+// Hint はエラー内容に応じた対処方法を返す（該当なしの場合は空文字）
+func (e *KintoneError) Hint() string {
+	switch e.Code {
+	case "CB_NO02":
+		return "アプリの管理権限がありません。アプリ管理者に権限の付与を依頼するか、API トークンにアプリ管理権限を付与してください"
+	case "GAIA_NO01":
+		return "API トークンにこの操作の権限がありません。アプリ管理権限を付与した API トークンを使用してください"
+	case "GAIA_IA02", "GAIA_IA01":
+		return "API トークンが正しくないか、対象アプリのトークンではありません"
+	case "CB_WA01", "CB_AU01":
+		return "ユーザー名またはパスワードが正しくありません。SSO / 2要素認証の環境では API トークンを使用してください"
+	case "GAIA_AP01":
+		return "アプリが見つかりません。アプリ ID やゲストスペース ID を確認してください"
+	case "GAIA_TM12":
+		return "同時リクエスト数の上限に達しました。しばらく待ってから再実行してください"
+	}
+
+	if strings.Contains(e.Message, "メンテナンス") || e.StatusCode == http.StatusServiceUnavailable {
+		return "kintone またはアプリがメンテナンス中です。時間をおいて再実行してください"
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "認証に失敗しました。Basic 認証やクライアント証明書の設定を確認してください"
+	case http.StatusForbidden:
+		return "アクセスが拒否されました。権限や IP 制限、セキュアアクセスの設定を確認してください"
+	case http.StatusTooManyRequests:
+		return "リクエスト数の上限に達しました。しばらく待ってから再実行してください"
+	}
+	return ""
+}
+
+// retryable はリトライで解消する可能性があるエラーかを返す
+// idempotent でない操作は、サーバーが処理していないことが明らかな場合のみリトライする
+func (e *KintoneError) retryable(idempotent bool) bool {
+	if e.Code == "GAIA_TM12" {
+		return true
+	}
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// ErrorHint は err に含まれる KintoneError の対処方法を返す
+func ErrorHint(err error) string {
+	var kerr *KintoneError
+	if errors.As(err, &kerr) {
+		return kerr.Hint()
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package kintone
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseKintoneError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantCode   string
+		wantMsg    string
+		wantError  string
+	}{
+		{
+			name:       "JSON のエラーレスポンス",
+			statusCode: http.StatusForbidden,
+			body:       `{"code":"CB_NO02","id":"abc","message":"権限がありません。"}`,
+			wantCode:   "CB_NO02",
+			wantMsg:    "権限がありません。",
+			wantError:  "カスタマイズ更新エラー: [CB_NO02] 権限がありません。 (id: abc)",
+		},
+		{
+			name:       "パラメータごとのエラーは名前順",
+			statusCode: http.StatusBadRequest,
+			body:       `{"code":"CB_VA01","message":"入力内容が正しくありません。","errors":{"desktop.js[1]":{"messages":["b"]},"app":{"messages":["a1","a2"]}}}`,
+			wantCode:   "CB_VA01",
+			wantMsg:    "入力内容が正しくありません。",
+			wantError:  "カスタマイズ更新エラー: [CB_VA01] 入力内容が正しくありません。\n  app: a1, a2\n  desktop.js[1]: b",
+		},
+		{
+			name:       "JSON でないボディは先頭をメッセージにする",
+			statusCode: http.StatusUnauthorized,
+			body:       "  <html>Basic 認証が必要です</html>\n",
+			wantMsg:    "<html>Basic 認証が必要です</html>",
+			wantError:  "カスタマイズ更新エラー: <html>Basic 認証が必要です</html>",
+		},
+		{
+			name:       "長いボディは切り詰める",
+			statusCode: http.StatusBadGateway,
+			body:       strings.Repeat("x", 300),
+			wantMsg:    strings.Repeat("x", 200) + "...",
+			wantError:  "カスタマイズ更新エラー: " + strings.Repeat("x", 200) + "...",
+		},
+		{
+			name:       "空のボディはステータスの説明",
+			statusCode: http.StatusServiceUnavailable,
+			body:       "",
+			wantMsg:    "Service Unavailable",
+			wantError:  "カスタマイズ更新エラー: Service Unavailable",
+		},
+		{
+			name:       "code も message も無い JSON",
+			statusCode: http.StatusInternalServerError,
+			body:       `{}`,
+			wantMsg:    "{}",
+			wantError:  "カスタマイズ更新エラー: {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := parseKintoneError("カスタマイズ更新", tt.statusCode, []byte(tt.body))
+			if e.StatusCode != tt.statusCode || e.Code != tt.wantCode || e.Message != tt.wantMsg {
+				t.Errorf("parseKintoneError() = {%d %q %q}, want {%d %q %q}", e.StatusCode, e.Code, e.Message, tt.statusCode, tt.wantCode, tt.wantMsg)
+			}
+			if got := e.Error(); got != tt.wantError {
+				t.Errorf("Error() = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestKintoneErrorHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *KintoneError
+		want string // ヒントに含まれる文字列（空の場合はヒントなし）
+	}{
+		{name: "アプリ管理権限なし", err: &KintoneError{StatusCode: 403, Code: "CB_NO02"}, want: "アプリの管理権限がありません"},
+		{name: "API トークンの権限不足", err: &KintoneError{StatusCode: 403, Code: "GAIA_NO01"}, want: "API トークンにこの操作の権限がありません"},
+		{name: "API トークンが正しくない", err: &KintoneError{StatusCode: 401, Code: "GAIA_IA02"}, want: "API トークンが正しくない"},
+		{name: "パスワード認証の失敗", err: &KintoneError{StatusCode: 401, Code: "CB_WA01"}, want: "ユーザー名またはパスワード"},
+		{name: "アプリが見つからない", err: &KintoneError{StatusCode: 404, Code: "GAIA_AP01"}, want: "ゲストスペース ID"},
+		{name: "同時リクエスト数の上限", err: &KintoneError{StatusCode: 503, Code: "GAIA_TM12"}, want: "同時リクエスト数"},
+		{name: "メンテナンス（メッセージ）", err: &KintoneError{StatusCode: 520, Message: "メンテナンス中です"}, want: "メンテナンス中"},
+		{name: "メンテナンス（503）", err: &KintoneError{StatusCode: 503}, want: "メンテナンス中"},
+		{name: "Basic 認証", err: &KintoneError{StatusCode: 401}, want: "Basic 認証"},
+		{name: "アクセス拒否", err: &KintoneError{StatusCode: 403}, want: "IP 制限"},
+		{name: "リクエスト数の上限", err: &KintoneError{StatusCode: 429}, want: "リクエスト数の上限"},
+		{name: "該当なし", err: &KintoneError{StatusCode: 400, Code: "CB_VA01"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.err.Hint()
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("Hint() = %q, want %q を含む", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorHint(t *testing.T) {
+	wrapped := fmt.Errorf("デプロイに失敗しました: %w", &KintoneError{StatusCode: 403, Code: "CB_NO02"})
+	if got := ErrorHint(wrapped); !strings.Contains(got, "アプリの管理権限") {
+		t.Errorf("ErrorHint(wrapped) = %q", got)
+	}
+	if got := ErrorHint(fmt.Errorf("other")); got != "" {
+		t.Errorf("ErrorHint(other) = %q, want empty", got)
+	}
+	if got := ErrorHint(nil); got != "" {
+		t.Errorf("ErrorHint(nil) = %q, want empty", got)
+	}
+}
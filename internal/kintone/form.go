@@ -0,0 +1,174 @@
+package kintone
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// FieldProperty は form/fields.json のフィールドの設定
+type FieldProperty struct {
+	Type     string                 `json:"type"`
+	Code     string                 `json:"code"`
+	Label    string                 `json:"label"`
+	Required bool                   `json:"required,omitempty"`
+	Options  map[string]FieldOption `json:"options,omitempty"`
+	// Fields はテーブル（SUBTABLE）内のフィールド
+	Fields map[string]FieldProperty `json:"fields,omitempty"`
+	// Lookup はルックアップの設定（ルックアップでない場合は nil）
+	Lookup *Lookup `json:"lookup,omitempty"`
+	// ReferenceTable は関連レコード一覧（REFERENCE_TABLE）の設定
+	ReferenceTable *ReferenceTable `json:"referenceTable,omitempty"`
+	// Enabled はプロセス管理のステータス・作業者、カテゴリーが有効か
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// FieldOption はラジオボタン・ドロップダウンなどの選択肢
+type FieldOption struct {
+	Label string `json:"label"`
+	Index string `json:"index"`
+}
+
+// RelatedApp はルックアップ・関連レコード一覧の参照先アプリ
+type RelatedApp struct {
+	App  string `json:"app"`
+	Code string `json:"code"`
+}
+
+// Lookup はルックアップの設定
+type Lookup struct {
+	RelatedApp      RelatedApp `json:"relatedApp"`
+	RelatedKeyField string     `json:"relatedKeyField"`
+}
+
+// ReferenceTable は関連レコード一覧の設定
+type ReferenceTable struct {
+	RelatedApp    RelatedApp `json:"relatedApp"`
+	DisplayFields []string   `json:"displayFields"`
+}
+
+// IsEnabled はステータスなどの機能が有効かを返す（enabled を持たないフィールドは true）
+func (p FieldProperty) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// OptionLabels は選択肢を kintone の表示順で返す
+func (p FieldProperty) OptionLabels() []string {
+	options := make([]FieldOption, 0, len(p.Options))
+	for _, o := range p.Options {
+		options = append(options, o)
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		a, _ := strconv.Atoi(options[i].Index)
+		b, _ := strconv.Atoi(options[j].Index)
+		if a != b {
+			return a < b
+		}
+		return options[i].Label < options[j].Label
+	})
+	labels := make([]string, len(options))
+	for i, o := range options {
+		labels[i] = o.Label
+	}
+	return labels
+}
+
+type formFieldsResponse struct {
+	Properties map[string]FieldProperty `json:"properties"`
+	Revision   string                   `json:"revision"`
+}
+
+// GetFormFields はアプリのフィールドの設定をフィールドコードごとに取得する
+func (c *Client) GetFormFields(ctx context.Context, appID int) (map[string]FieldProperty, error) {
+	var result formFieldsResponse
+	err := c.do(ctx, apiRequest{
+		operation:  "フィールド設定取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL("/app/form/fields.json"), appID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Properties, nil
+}
+
+// LayoutItem は form/layout.json の行（ROW / SUBTABLE / GROUP）
+type LayoutItem struct {
+	Type string `json:"type"`
+	// Code はテーブル・グループのフィールドコード
+	Code   string        `json:"code,omitempty"`
+	Fields []LayoutField `json:"fields,omitempty"`
+	// Layout はグループ内の行
+	Layout []LayoutItem `json:"layout,omitempty"`
+}
+
+// LayoutField は行に配置されたフィールド・ラベル・スペース
+type LayoutField struct {
+	Type      string `json:"type"`
+	Code      string `json:"code,omitempty"`
+	Label     string `json:"label,omitempty"`
+	ElementID string `json:"elementId,omitempty"`
+}
+
+type formLayoutResponse struct {
+	Layout   []LayoutItem `json:"layout"`
+	Revision string       `json:"revision"`
+}
+
+// GetFormLayout はアプリのフォームのレイアウトを取得する
+func (c *Client) GetFormLayout(ctx context.Context, appID int) ([]LayoutItem, error) {
+	var result formLayoutResponse
+	err := c.do(ctx, apiRequest{
+		operation:  "フォームレイアウト取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL("/app/form/layout.json"), appID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Layout, nil
+}
+
+// View はアプリの一覧の設定
+type View struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Index string `json:"index"`
+}
+
+type viewsResponse struct {
+	Views    map[string]View `json:"views"`
+	Revision string          `json:"revision"`
+}
+
+// GetViews はアプリの一覧を kintone の表示順で取得する
+func (c *Client) GetViews(ctx context.Context, appID int) ([]View, error) {
+	var result viewsResponse
+	err := c.do(ctx, apiRequest{
+		operation:  "一覧の設定取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL("/app/views.json"), appID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]View, 0, len(result.Views))
+	for _, v := range result.Views {
+		views = append(views, v)
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		a, _ := strconv.Atoi(views[i].Index)
+		b, _ := strconv.Atoi(views[j].Index)
+		if a != b {
+			return a < b
+		}
+		return views[i].Name < views[j].Name
+	})
+	return views, nil
+}
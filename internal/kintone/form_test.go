@@ -0,0 +1,84 @@
+package kintone
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOptionLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]FieldOption
+		want    []string
+	}{
+		{name: "選択肢なし", want: []string{}},
+		{
+			name: "index の数値順",
+			options: map[string]FieldOption{
+				"c": {Label: "c", Index: "10"},
+				"a": {Label: "a", Index: "2"},
+				"b": {Label: "b", Index: "0"},
+			},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name: "index が同じ場合はラベル順",
+			options: map[string]FieldOption{
+				"い": {Label: "い", Index: "1"},
+				"あ": {Label: "あ", Index: "1"},
+				"う": {Label: "う", Index: "0"},
+			},
+			want: []string{"う", "あ", "い"},
+		},
+		{
+			name: "index が数値でない場合は先頭",
+			options: map[string]FieldOption{
+				"x": {Label: "x", Index: "1"},
+				"y": {Label: "y", Index: ""},
+			},
+			want: []string{"y", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FieldProperty{Type: "DROP_DOWN", Options: tt.options}.OptionLabels()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OptionLabels() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetViews(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k/v1/app/views.json" || r.URL.Query().Get("app") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"views":{
+			"一覧C":{"id":"3","name":"一覧C","type":"LIST","index":"10"},
+			"一覧A":{"id":"1","name":"一覧A","type":"LIST","index":"2"},
+			"カレンダー":{"id":"5","name":"カレンダー","type":"CALENDAR","index":"0"},
+			"一覧B":{"id":"2","name":"一覧B","type":"LIST","index":"2"}
+		},"revision":"4"}`))
+	}))
+	defer srv.Close()
+
+	views, err := newTestClient(srv, 0).GetViews(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range views {
+		got = append(got, v.Name)
+	}
+	// index の数値順（同じ index は名前順）
+	want := []string{"カレンダー", "一覧A", "一覧B", "一覧C"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetViews() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package kintone
+
+import (
+	"context"
+	"strings"
+)
+
+// MergePosition は kcdev のファイルを既存のカスタマイズ内に新規追加する位置
+// 各要素はファイル名または URL の部分一致（大文字小文字を区別しない）で判定する
+type MergePosition struct {
+	// After に一致する最後のエントリより後ろに配置する
+	After []string
+	// Before に一致する最初のエントリより前に配置する
+	Before []string
+}
+
+// UpdateCustomizeMerge は既存のカスタマイズ設定を維持したまま kcdev のファイルだけを差し替える
+//
+// managed に一致するファイル名・URL は kcdev 管理とみなし、その位置に新しいファイルを配置する。
+// 管理ファイルがまだ無い場合は pos に従って挿入する。それ以外のファイルや URL は
+// 取得した fileKey のまま残す。
+func (c *Client) UpdateCustomizeMerge(ctx context.Context, appID int, desktopFiles, mobileFiles *CustomizeFiles, scope CustomizeScope, managed []string, pos MergePosition) error {
+	current, err := c.GetCustomize(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	customize := mergeCustomize(current, appID, desktopFiles, mobileFiles, scope, managed, pos)
+	return c.updateCustomizeRequest(ctx, customize)
+}
+
+// mergeCustomize は現在のカスタマイズ設定に kcdev のファイルを統合した更新リクエストを作る
+func mergeCustomize(current *CustomizeResponse, appID int, desktopFiles, mobileFiles *CustomizeFiles, scope CustomizeScope, managed []string, pos MergePosition) CustomizeRequest {
+	isManaged := func(f FileCustomizationResponse) bool {
+		return isManagedEntry(f, managed)
+	}
+
+	return CustomizeRequest{
+		App:     appID,
+		Scope:   scope,
+		Desktop: mergeDesktopMobile(current.Desktop, desktopFiles, isManaged, pos),
+		Mobile:  mergeDesktopMobile(current.Mobile, mobileFiles, isManaged, pos),
+	}
+}
+
+func mergeDesktopMobile(current *CustomizeDesktopMobileResponse, files *CustomizeFiles, isManaged func(FileCustomizationResponse) bool, pos MergePosition) *CustomizeDesktopMobile {
+	if current == nil {
+		current = &CustomizeDesktopMobileResponse{}
+	}
+
+	return &CustomizeDesktopMobile{
+		JS:  mergeFileList(current.JS, files.jsList(), isManaged, pos),
+		CSS: mergeFileList(current.CSS, files.cssList(), isManaged, pos),
+	}
+}
+
+// mergeFileList は既存のリストから kcdev 管理ファイルを取り除き、entries をまとめて配置する
+// entries が空の場合は管理ファイルを取り除くだけ
+func mergeFileList(current []FileCustomizationResponse, entries []FileCustomization, isManaged func(FileCustomizationResponse) bool, pos MergePosition) []FileCustomization {
+	var kept []FileCustomizationResponse
+	insertAt := -1
+
+	for _, f := range current {
+		if isManaged(f) {
+			// 最初の管理ファイルの位置をそのまま引き継ぐ
+			if insertAt < 0 {
+				insertAt = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if insertAt < 0 {
+		insertAt = insertPosition(kept, pos)
+	}
+
+	result := []FileCustomization{}
+	for i, f := range kept {
+		if i == insertAt {
+			result = append(result, entries...)
+		}
+		result = append(result, keepEntry(f))
+	}
+	if insertAt == len(kept) {
+		result = append(result, entries...)
+	}
+	return result
+}
+
+// isManagedEntry は既存のエントリが managed のファイル名または URL に一致するかを返す
+func isManagedEntry(f FileCustomizationResponse, managed []string) bool {
+	target := f.URL
+	if f.Type == "FILE" {
+		if f.File == nil {
+			return false
+		}
+		target = f.File.Name
+	}
+	for _, m := range managed {
+		if target == m {
+			return true
+		}
+	}
+	return false
+}
+
+// keepEntry は既存のエントリを更新リクエスト用に変換する（FILE は既存の fileKey を再利用する）
+func keepEntry(f FileCustomizationResponse) FileCustomization {
+	if f.Type == "FILE" && f.File != nil {
+		return FileCustomization{Type: "FILE", File: &File{FileKey: f.File.FileKey}}
+	}
+	return FileCustomization{Type: f.Type, URL: f.URL}
+}
+
+// insertPosition は pos に従って新規ファイルを挿入するインデックスを返す
+// 指定が無い、または一致するエントリが無い場合は末尾
+func insertPosition(list []FileCustomizationResponse, pos MergePosition) int {
+	at := len(list)
+
+	if len(pos.After) > 0 {
+		for i := len(list) - 1; i >= 0; i-- {
+			if matchesAny(list[i], pos.After) {
+				at = i + 1
+				break
+			}
+		}
+	}
+
+	if len(pos.Before) > 0 {
+		for i, f := range list {
+			if matchesAny(f, pos.Before) {
+				// After と矛盾する場合は Before を優先する
+				if i < at {
+					at = i
+				}
+				break
+			}
+		}
+	}
+
+	return at
+}
+
+func matchesAny(f FileCustomizationResponse, patterns []string) bool {
+	target := f.URL
+	if f.File != nil {
+		target = f.File.Name
+	}
+	target = strings.ToLower(target)
+	for _, p := range patterns {
+		if p != "" && strings.Contains(target, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package kintone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func fileEntry(name, fileKey string) FileCustomizationResponse {
+	return FileCustomizationResponse{Type: "FILE", File: &FileResponse{Name: name, FileKey: fileKey}}
+}
+
+func urlEntry(url string) FileCustomizationResponse {
+	return FileCustomizationResponse{Type: "URL", URL: url}
+}
+
+func TestInsertPosition(t *testing.T) {
+	list := []FileCustomizationResponse{
+		urlEntry("https://js.cybozu.com/jquery/3.7.1/jquery.min.js"),
+		fileEntry("plugin-a.js", "k1"),
+		urlEntry("https://js.cybozu.com/jquery/3.7.1/jquery-ui.min.js"),
+		fileEntry("after-all.js", "k2"),
+	}
+
+	tests := []struct {
+		name string
+		pos  MergePosition
+		want int
+	}{
+		{name: "指定なしは末尾", pos: MergePosition{}, want: 4},
+		{name: "After は一致する最後のエントリの後ろ", pos: MergePosition{After: []string{"jquery"}}, want: 3},
+		{name: "Before は一致する最初のエントリの前", pos: MergePosition{Before: []string{"after-all"}}, want: 3},
+		{name: "大文字小文字を区別しない", pos: MergePosition{Before: []string{"PLUGIN-A"}}, want: 1},
+		{name: "After と Before が矛盾する場合は Before を優先", pos: MergePosition{After: []string{"jquery-ui"}, Before: []string{"plugin-a"}}, want: 1},
+		{name: "Before が After より後ろなら After の位置", pos: MergePosition{After: []string{"jquery.min"}, Before: []string{"after-all"}}, want: 1},
+		{name: "一致しない場合は末尾", pos: MergePosition{After: []string{"nothing"}, Before: []string{"missing"}}, want: 4},
+		{name: "空のパターンは一致させない", pos: MergePosition{Before: []string{""}}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertPosition(list, tt.pos); got != tt.want {
+				t.Errorf("insertPosition() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFileList(t *testing.T) {
+	managed := []string{"kcdev-loader.js", "https://localhost:3000/main.js"}
+	isManaged := func(f FileCustomizationResponse) bool {
+		return isManagedEntry(f, managed)
+	}
+	entries := []FileCustomization{{Type: "FILE", File: &File{FileKey: "new"}}}
+	kept := func(fileKey string) FileCustomization {
+		return FileCustomization{Type: "FILE", File: &File{FileKey: fileKey}}
+	}
+
+	tests := []struct {
+		name    string
+		current []FileCustomizationResponse
+		entries []FileCustomization
+		pos     MergePosition
+		want    []FileCustomization
+	}{
+		{
+			name:    "既存が空なら entries だけ",
+			entries: entries,
+			want:    entries,
+		},
+		{
+			name:    "管理ファイルの位置を引き継ぐ",
+			current: []FileCustomizationResponse{fileEntry("a.js", "a"), fileEntry("kcdev-loader.js", "old"), fileEntry("b.js", "b")},
+			entries: entries,
+			want:    []FileCustomization{kept("a"), entries[0], kept("b")},
+		},
+		{
+			name:    "複数の管理ファイルは最初の位置にまとめる",
+			current: []FileCustomizationResponse{urlEntry("https://localhost:3000/main.js"), fileEntry("a.js", "a"), fileEntry("kcdev-loader.js", "old")},
+			entries: entries,
+			want:    []FileCustomization{entries[0], kept("a")},
+		},
+		{
+			name:    "管理ファイルが無い場合は pos に従う",
+			current: []FileCustomizationResponse{urlEntry("https://js.cybozu.com/jquery.min.js"), fileEntry("a.js", "a")},
+			entries: entries,
+			pos:     MergePosition{After: []string{"jquery"}},
+			want:    []FileCustomization{{Type: "URL", URL: "https://js.cybozu.com/jquery.min.js"}, entries[0], kept("a")},
+		},
+		{
+			name:    "管理ファイルが無く pos も無い場合は末尾",
+			current: []FileCustomizationResponse{fileEntry("a.js", "a")},
+			entries: entries,
+			want:    []FileCustomization{kept("a"), entries[0]},
+		},
+		{
+			name:    "entries が空なら管理ファイルを取り除くだけ",
+			current: []FileCustomizationResponse{fileEntry("kcdev-loader.js", "old"), fileEntry("a.js", "a")},
+			want:    []FileCustomization{kept("a")},
+		},
+		{
+			name:    "ファイル名の一部だけ一致するものは残す",
+			current: []FileCustomizationResponse{fileEntry("my-kcdev-loader.js", "x"), fileEntry("kcdev-loader.js", "old")},
+			entries: entries,
+			want:    []FileCustomization{kept("x"), entries[0]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeFileList(tt.current, tt.entries, isManaged, tt.pos)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeFileList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
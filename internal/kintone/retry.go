@@ -0,0 +1,88 @@
+package kintone
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy は一時的なエラーに対するリトライ設定
+type RetryPolicy struct {
+	// MaxRetries は最大リトライ回数（0 でリトライしない）
+	MaxRetries int
+	// BaseDelay は初回リトライまでの待ち時間の上限。以降は指数的に増加する
+	BaseDelay time.Duration
+	// MaxDelay は待ち時間の上限
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy はリトライ設定が指定されない場合の既定値
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   8 * time.Second,
+}
+
+// delay は attempt 回目（0 始まり）のリトライまでの待ち時間を返す
+// Full Jitter 方式で、Retry-After ヘッダーがあればそちらを優先する
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+			d := time.Duration(sec) * time.Second
+			if p.MaxDelay > 0 && d > p.MaxDelay {
+				return p.MaxDelay
+			}
+			return d
+		}
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff))) + 1
+}
+
+// retryableNetError はネットワークエラーがリトライ可能かを返す
+// 接続確立前の失敗はリクエストが送信されていないため常にリトライでき、
+// 送信後の切断やタイムアウトは idempotent な操作のみリトライする
+func retryableNetError(err error, idempotent bool) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.Temporary() {
+		return true
+	}
+
+	if !idempotent {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sleepContext は d だけ待機する。ctx がキャンセルされた場合は即座に ctx.Err() を返す
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
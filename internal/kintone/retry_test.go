@@ -0,0 +1,94 @@
+package kintone
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	retryAfter := func(v string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{v}}}
+	}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		resp    *http.Response
+		// 待ち時間は (min, max] の範囲（Full Jitter）。min == max の場合は固定値
+		min, max time.Duration
+	}{
+		{name: "初回は BaseDelay まで", policy: policy, attempt: 0, max: 100 * time.Millisecond},
+		{name: "指数的に増加", policy: policy, attempt: 2, max: 400 * time.Millisecond},
+		{name: "MaxDelay で頭打ち", policy: policy, attempt: 5, max: time.Second},
+		{name: "シフトが溢れても MaxDelay", policy: policy, attempt: 80, max: time.Second},
+		{name: "Retry-After を優先", policy: policy, attempt: 0, resp: retryAfter("1"), min: time.Second, max: time.Second},
+		{name: "Retry-After も MaxDelay で頭打ち", policy: RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}, resp: retryAfter("30"), min: 2 * time.Second, max: 2 * time.Second},
+		{name: "日付形式の Retry-After は無視", policy: policy, attempt: 0, resp: retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"), max: 100 * time.Millisecond},
+		{name: "待ち時間が 0 の設定", policy: RetryPolicy{MaxRetries: 3}, attempt: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := tt.policy.delay(tt.attempt, tt.resp)
+				if tt.min == tt.max {
+					if got != tt.max {
+						t.Fatalf("delay() = %v, want %v", got, tt.max)
+					}
+					continue
+				}
+				if got <= tt.min || got > tt.max {
+					t.Fatalf("delay() = %v, want (%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// timeoutError は net.Error のタイムアウト
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableNetError(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	tests := []struct {
+		name       string
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		{name: "接続確立前の失敗", err: dialErr, want: true},
+		{name: "ラップされた接続確立前の失敗", err: fmt.Errorf("post: %w", dialErr), want: true},
+		{name: "一時的な DNS エラー", err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}, want: true},
+		{name: "恒久的な DNS エラー", err: &net.DNSError{Err: "no such host", IsNotFound: true}, idempotent: true, want: false},
+		{name: "送信後の切断（idempotent）", err: resetErr, idempotent: true, want: true},
+		{name: "送信後の切断（idempotent でない）", err: resetErr, want: false},
+		{name: "途中で切れたレスポンス", err: io.ErrUnexpectedEOF, idempotent: true, want: true},
+		{name: "レスポンスなし", err: fmt.Errorf("post: %w", io.EOF), idempotent: true, want: true},
+		{name: "タイムアウト（idempotent）", err: timeoutError{}, idempotent: true, want: true},
+		{name: "タイムアウト（idempotent でない）", err: timeoutError{}, want: false},
+		{name: "そのほかのエラー", err: errors.New("x509: certificate signed by unknown authority"), idempotent: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableNetError(tt.err, tt.idempotent); got != tt.want {
+				t.Errorf("retryableNetError(%v, %v) = %v, want %v", tt.err, tt.idempotent, got, tt.want)
+			}
+		})
+	}
+}
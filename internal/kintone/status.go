@@ -0,0 +1,78 @@
+package kintone
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ProcessManagement は /k/v1/app/status.json のプロセス管理の設定
+type ProcessManagement struct {
+	Enable  bool                    `json:"enable"`
+	States  map[string]ProcessState `json:"states"`
+	Actions []ProcessAction         `json:"actions"`
+}
+
+// ProcessState はプロセス管理のステータス
+type ProcessState struct {
+	Name  string `json:"name"`
+	Index string `json:"index"`
+}
+
+// ProcessAction はステータスを進めるアクション（From から To への遷移）
+type ProcessAction struct {
+	Name       string `json:"name"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	FilterCond string `json:"filterCond"`
+}
+
+// StateNames はステータス名を kintone の表示順で返す
+func (p *ProcessManagement) StateNames() []string {
+	states := make([]ProcessState, 0, len(p.States))
+	for _, s := range p.States {
+		states = append(states, s)
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		a, _ := strconv.Atoi(states[i].Index)
+		b, _ := strconv.Atoi(states[j].Index)
+		if a != b {
+			return a < b
+		}
+		return states[i].Name < states[j].Name
+	})
+	names := make([]string, len(states))
+	for i, s := range states {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// ActionNames はアクション名を設定順に重複なく返す
+func (p *ProcessManagement) ActionNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, a := range p.Actions {
+		if !seen[a.Name] {
+			seen[a.Name] = true
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// GetProcessManagement はアプリのプロセス管理の設定を取得する
+func (c *Client) GetProcessManagement(ctx context.Context, appID int) (*ProcessManagement, error) {
+	var result ProcessManagement
+	err := c.do(ctx, apiRequest{
+		operation:  "プロセス管理の設定取得",
+		method:     "GET",
+		url:        fmt.Sprintf("%s?app=%d", c.apiURL("/app/status.json"), appID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
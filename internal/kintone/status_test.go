@@ -0,0 +1,56 @@
+package kintone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStateNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		states map[string]ProcessState
+		want   []string
+	}{
+		{name: "ステータスなし", want: []string{}},
+		{
+			name: "index の数値順",
+			states: map[string]ProcessState{
+				"完了":  {Name: "完了", Index: "10"},
+				"処理中": {Name: "処理中", Index: "2"},
+				"未処理": {Name: "未処理", Index: "0"},
+			},
+			want: []string{"未処理", "処理中", "完了"},
+		},
+		{
+			name: "index が同じ場合は名前順",
+			states: map[string]ProcessState{
+				"b": {Name: "b", Index: "1"},
+				"a": {Name: "a", Index: "1"},
+			},
+			want: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&ProcessManagement{Enable: true, States: tt.states}).StateNames()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StateNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionNames(t *testing.T) {
+	p := &ProcessManagement{Actions: []ProcessAction{
+		{Name: "処理開始", From: "未処理", To: "処理中"},
+		{Name: "差し戻す", From: "処理中", To: "未処理"},
+		{Name: "完了する", From: "処理中", To: "完了"},
+		{Name: "差し戻す", From: "完了", To: "未処理"},
+	}}
+	// 設定順で、同じ名前のアクションは 1 つにまとめる
+	want := []string{"処理開始", "差し戻す", "完了する"}
+	if got := p.ActionNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ActionNames() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package kintone
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ClientOptions は kintone クライアントの接続設定
+type ClientOptions struct {
+	Domain string
+	Auth   Auth
+	// GuestSpaceID はゲストスペース内のアプリを操作する場合に指定する
+	GuestSpaceID int
+
+	// BasicAuth は kintone の前段にある Basic 認証の資格情報
+	BasicAuth *BasicAuth
+	// ClientCert はセキュアアクセス用のクライアント証明書
+	ClientCert *ClientCert
+	// CAFile は追加で信頼する CA 証明書バンドル (PEM)
+	CAFile string
+	// Proxy は HTTP(S) プロキシ。未指定の場合は HTTPS_PROXY などの環境変数に従う
+	Proxy *Proxy
+	// Retry は一時的なエラーのリトライ設定。未指定の場合は DefaultRetryPolicy を使う
+	Retry *RetryPolicy
+}
+
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// ClientCert はクライアント証明書の設定
+// File が PKCS#12 (.pfx/.p12) の場合は Password で復号し、PEM の場合は KeyFile と組み合わせる
+type ClientCert struct {
+	File     string
+	KeyFile  string
+	Password string
+}
+
+type Proxy struct {
+	URL      string
+	Username string
+	Password string
+}
+
+func newHTTPClient(opts ClientOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.ClientCert != nil {
+		cert, err := loadClientCert(opts.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント証明書の読み込みエラー: %w", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if opts.CAFile != "" {
+		pool, err := loadCertPool(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA 証明書の読み込みエラー: %w", err)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if opts.Proxy != nil && opts.Proxy.URL != "" {
+		proxyURL, err := url.Parse(opts.Proxy.URL)
+		if err != nil {
+			return nil, fmt.Errorf("プロキシ URL が不正です: %w", err)
+		}
+		// 資格情報は URL の userinfo として渡すと CONNECT 時にも Proxy-Authorization が付与される
+		if opts.Proxy.Username != "" {
+			proxyURL.User = url.UserPassword(opts.Proxy.Username, opts.Proxy.Password)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+	}, nil
+}
+
+func loadClientCert(cc *ClientCert) (tls.Certificate, error) {
+	data, err := os.ReadFile(cc.File)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(cc.File))
+	if ext == ".pfx" || ext == ".p12" || !isPEM(data) {
+		key, leaf, chain, err := pkcs12.DecodeChain(data, cc.Password)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+		cert := tls.Certificate{
+			Certificate: [][]byte{leaf.Raw},
+			PrivateKey:  key,
+			Leaf:        leaf,
+		}
+		for _, c := range chain {
+			cert.Certificate = append(cert.Certificate, c.Raw)
+		}
+		return cert, nil
+	}
+
+	keyFile := cc.KeyFile
+	if keyFile == "" {
+		// 証明書と秘密鍵が同じ PEM ファイルに含まれている場合
+		keyFile = cc.File
+	}
+	return tls.LoadX509KeyPair(cc.File, keyFile)
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s に有効な証明書がありません", caFile)
+	}
+	return pool, nil
+}
+
+func isPEM(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil
+}
+
+func basicAuthHeader(ba *BasicAuth) string {
+	if ba == nil || ba.Username == "" {
+		return ""
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(ba.Username+":"+ba.Password))
+}
+
+// SecureAccessDomain はクライアント証明書を使う場合のセキュアアクセス用ドメインを返す
+// 例: example.cybozu.com → example.s.cybozu.com
+func SecureAccessDomain(domain string) string {
+	for _, suffix := range []string{".cybozu.com", ".kintone.com", ".cybozu.cn"} {
+		if !strings.HasSuffix(domain, suffix) {
+			continue
+		}
+		sub := strings.TrimSuffix(domain, suffix)
+		if strings.HasSuffix(sub, ".s") {
+			return domain
+		}
+		return sub + ".s" + suffix
+	}
+	return domain
+}
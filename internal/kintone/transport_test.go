@@ -0,0 +1,27 @@
+package kintone
+
+import "testing"
+
+func TestSecureAccessDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{domain: "example.cybozu.com", want: "example.s.cybozu.com"},
+		{domain: "example.kintone.com", want: "example.s.kintone.com"},
+		{domain: "example.cybozu.cn", want: "example.s.cybozu.cn"},
+		{domain: "example.s.cybozu.com", want: "example.s.cybozu.com"},
+		{domain: "example.s.kintone.com", want: "example.s.kintone.com"},
+		{domain: "example.cybozu-dev.com", want: "example.cybozu-dev.com"},
+		{domain: "kintone.example.jp", want: "kintone.example.jp"},
+		{domain: "localhost:8080", want: "localhost:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := SecureAccessDomain(tt.domain); got != tt.want {
+				t.Errorf("SecureAccessDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
@@ -44,15 +44,24 @@ const (
 	ScopeNone  Scope = "NONE"
 )
 
+type AuthMethod string
+
+const (
+	AuthMethodPassword AuthMethod = "password"
+	AuthMethodAPIToken AuthMethod = "apiToken"
+)
+
 type InitAnswers struct {
 	ProjectName    string
 	CreateDir      bool
 	Domain         string
 	AppID          int
+	GuestSpaceID   int
 	Framework      Framework
 	Language       Language
 	Username       string
 	Password       string
+	APIToken       string
 	PackageManager PackageManager
 	TargetDesktop  bool
 	TargetMobile   bool
@@ -190,6 +199,37 @@ func AskAppID(defaultVal int) (int, error) {
 	return strconv.Atoi(answer)
 }
 
+// AskGuestSpaceID はゲストスペース ID を入力させる（空欄の場合は 0 = 通常アプリ）
+func AskGuestSpaceID(defaultVal int) (int, error) {
+	answer := ""
+	if defaultVal > 0 {
+		answer = strconv.Itoa(defaultVal)
+	}
+	err := newForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("ゲストスペース ID").
+				Description("ゲストスペース内のアプリの場合のみ入力（通常のアプリは空欄）").
+				Value(&answer).
+				Validate(func(s string) error {
+					if s != "" {
+						if _, err := strconv.Atoi(s); err != nil {
+							return err
+						}
+					}
+					return nil
+				}),
+		),
+	).Run()
+	if err != nil {
+		return 0, err
+	}
+	if answer == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(answer)
+}
+
 func AskFramework() (Framework, error) {
 	return AskFrameworkExcept("")
 }
@@ -294,6 +334,48 @@ func AskPassword() (string, error) {
 	return answer, nil
 }
 
+func AskAuthMethod() (AuthMethod, error) {
+	var answer AuthMethod
+	err := newForm(
+		huh.NewGroup(
+			huh.NewSelect[AuthMethod]().
+				Title("認証方式").
+				Options(
+					huh.NewOption("パスワード認証", AuthMethodPassword),
+					huh.NewOption("API トークン（SSO / 2要素認証環境向け）", AuthMethodAPIToken),
+				).
+				Value(&answer),
+		),
+	).Run()
+	if err != nil {
+		return "", err
+	}
+	return answer, nil
+}
+
+func AskAPIToken() (string, error) {
+	var answer string
+	err := newForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("kintone API トークン").
+				Description("複数指定する場合はカンマ区切り（アプリ管理権限が必要です）").
+				EchoMode(huh.EchoModePassword).
+				Value(&answer).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errRequired
+					}
+					return nil
+				}),
+		),
+	).Run()
+	if err != nil {
+		return "", err
+	}
+	return answer, nil
+}
+
 func AskPackageManager() (PackageManager, error) {
 	redStyle := lipgloss.NewStyle().Foreground(colorRed)
 	cyanStyle := lipgloss.NewStyle().Foreground(colorCyan)
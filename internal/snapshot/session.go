@@ -0,0 +1,75 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kintone/kcdev/internal/config"
+)
+
+const sessionFile = "dev-session.json"
+
+// DevSession は kcdev dev がローダーをデプロイしている間の状態
+// 終了時（または異常終了後の kcdev dev --restore）に SnapshotID の設定へ戻す
+type DevSession struct {
+	SnapshotID  string    `json:"snapshotId"`
+	StartedAt   time.Time `json:"startedAt"`
+	PreviewOnly bool      `json:"previewOnly"`
+}
+
+func sessionPath(projectDir string) string {
+	return filepath.Join(projectDir, config.ConfigDir, sessionFile)
+}
+
+// LoadSession は記録されている dev セッションを返す（無い場合は nil）
+func LoadSession(projectDir string) (*DevSession, error) {
+	data, err := os.ReadFile(sessionPath(projectDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var s DevSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SaveSession は dev セッションを記録する
+func SaveSession(projectDir string, s *DevSession) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sessionPath(projectDir), data, 0644)
+}
+
+// RecordDevDeploy は kcdev dev がローダーをデプロイしたことを dev セッションに記録し、記録したセッションを返す
+// session が nil の場合は snapshotID を復元対象とする新しいセッションを作る
+// 前回のセッションを引き継ぐ場合は復元対象を変えず、本番環境にもデプロイした場合は PreviewOnly を外す
+func RecordDevDeploy(projectDir string, session *DevSession, snapshotID string, previewOnly bool) (*DevSession, error) {
+	if session == nil {
+		session = &DevSession{
+			SnapshotID:  snapshotID,
+			StartedAt:   time.Now(),
+			PreviewOnly: previewOnly,
+		}
+	} else {
+		session.PreviewOnly = session.PreviewOnly && previewOnly
+	}
+	return session, SaveSession(projectDir, session)
+}
+
+// ClearSession は dev セッションの記録を削除する
+func ClearSession(projectDir string) error {
+	err := os.Remove(sessionPath(projectDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kintone/kcdev/internal/config"
+)
+
+func TestRecordDevDeploy(t *testing.T) {
+	tests := []struct {
+		name string
+		// crashed は異常終了した前回の dev セッション（無い場合は nil）
+		crashed         *DevSession
+		previewOnly     bool
+		wantSnapshotID  string
+		wantPreviewOnly bool
+	}{
+		{
+			name:            "新しいセッション",
+			previewOnly:     true,
+			wantSnapshotID:  "new",
+			wantPreviewOnly: true,
+		},
+		{
+			name:            "プレビューのみのセッションの後に本番環境へデプロイ",
+			crashed:         &DevSession{SnapshotID: "crashed", PreviewOnly: true},
+			previewOnly:     false,
+			wantSnapshotID:  "crashed",
+			wantPreviewOnly: false,
+		},
+		{
+			name:            "プレビューのみのセッションの後にプレビューのみへデプロイ",
+			crashed:         &DevSession{SnapshotID: "crashed", PreviewOnly: true},
+			previewOnly:     true,
+			wantSnapshotID:  "crashed",
+			wantPreviewOnly: true,
+		},
+		{
+			name:            "本番環境のセッションの後にプレビューのみへデプロイ",
+			crashed:         &DevSession{SnapshotID: "crashed", PreviewOnly: false},
+			previewOnly:     true,
+			wantSnapshotID:  "crashed",
+			wantPreviewOnly: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(projectDir, config.ConfigDir), 0755); err != nil {
+				t.Fatal(err)
+			}
+			session := tt.crashed
+			if session != nil {
+				session.StartedAt = time.Now().Add(-time.Hour)
+				if err := SaveSession(projectDir, session); err != nil {
+					t.Fatal(err)
+				}
+				// kcdev dev の起動時と同じく、記録されているセッションを読み込んで引き継ぐ
+				loaded, err := LoadSession(projectDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				session = loaded
+			}
+
+			if _, err := RecordDevDeploy(projectDir, session, "new", tt.previewOnly); err != nil {
+				t.Fatal(err)
+			}
+
+			// 終了時の復元は保存されたセッションを読み込んで行う
+			got, err := LoadSession(projectDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.SnapshotID != tt.wantSnapshotID || got.PreviewOnly != tt.wantPreviewOnly {
+				t.Errorf("LoadSession() = {SnapshotID: %q, PreviewOnly: %v}, want {SnapshotID: %q, PreviewOnly: %v}",
+					got.SnapshotID, got.PreviewOnly, tt.wantSnapshotID, tt.wantPreviewOnly)
+			}
+		})
+	}
+}
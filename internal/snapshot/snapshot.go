@@ -0,0 +1,234 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kintone/kcdev/internal/config"
+	"github.com/kintone/kcdev/internal/kintone"
+)
+
+const (
+	BackupDir    = "backups"
+	metaFile     = "snapshot.json"
+	filesDir     = "files"
+	idTimeFormat = "20060102-150405"
+)
+
+// Snapshot はデプロイ直前のアプリのカスタマイズ設定
+type Snapshot struct {
+	ID        string                     `json:"id"`
+	CreatedAt time.Time                  `json:"createdAt"`
+	AppID     int                        `json:"appId"`
+	Domain    string                     `json:"domain"`
+	Command   string                     `json:"command"`
+	Customize *kintone.CustomizeResponse `json:"customize"`
+}
+
+// Dir はプロジェクトのスナップショット保存先を返す
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, config.ConfigDir, BackupDir)
+}
+
+func (s *Snapshot) dir(projectDir string) string {
+	return filepath.Join(Dir(projectDir), s.ID)
+}
+
+// filePath は fileKey に対応する保存ファイルのパスを返す
+// 再アップロード時に元のファイル名を使うため、fileKey ごとのディレクトリに元の名前で保存する
+func (s *Snapshot) filePath(projectDir string, f *kintone.FileResponse) string {
+	return filepath.Join(s.dir(projectDir), filesDir, f.FileKey, filepath.Base(f.Name))
+}
+
+// Files はスナップショットに含まれるファイルの一覧を返す（同じ fileKey は 1 件にまとめる）
+func (s *Snapshot) Files() []*kintone.FileResponse {
+	var files []*kintone.FileResponse
+	seen := map[string]bool{}
+	for _, set := range []*kintone.CustomizeDesktopMobileResponse{s.Customize.Desktop, s.Customize.Mobile} {
+		if set == nil {
+			continue
+		}
+		for _, list := range [][]kintone.FileCustomizationResponse{set.JS, set.CSS} {
+			for _, f := range list {
+				if f.Type != "FILE" || f.File == nil || seen[f.File.FileKey] {
+					continue
+				}
+				seen[f.File.FileKey] = true
+				files = append(files, f.File)
+			}
+		}
+	}
+	return files
+}
+
+// Summary は一覧表示用の概要を返す
+func (s *Snapshot) Summary() string {
+	count := func(set *kintone.CustomizeDesktopMobileResponse) (int, int) {
+		if set == nil {
+			return 0, 0
+		}
+		return len(set.JS), len(set.CSS)
+	}
+	dj, dc := count(s.Customize.Desktop)
+	mj, mc := count(s.Customize.Mobile)
+	return fmt.Sprintf("%s (%s) デスクトップ JS:%d CSS:%d / モバイル JS:%d CSS:%d / %s",
+		s.ID, s.Command, dj, dc, mj, mc, s.Customize.Scope)
+}
+
+// Create は現在のカスタマイズ設定と参照されているファイルを保存する
+// 古いスナップショットの削除は Prune で行う
+func Create(ctx context.Context, projectDir string, client *kintone.Client, cfg *config.Config, command string) (*Snapshot, error) {
+	customize, err := client.GetCustomize(ctx, cfg.Kintone.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s := &Snapshot{
+		ID:        uniqueID(projectDir, now),
+		CreatedAt: now,
+		AppID:     cfg.Kintone.AppID,
+		Domain:    cfg.Kintone.Domain,
+		Command:   command,
+		Customize: customize,
+	}
+
+	if err := os.MkdirAll(s.dir(projectDir), 0755); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, projectDir, client); err != nil {
+		// 不完全なスナップショットは残さない
+		os.RemoveAll(s.dir(projectDir))
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// save は参照されているファイルをダウンロードし、メタデータと一緒に保存する
+func (s *Snapshot) save(ctx context.Context, projectDir string, client *kintone.Client) error {
+	for _, f := range s.Files() {
+		data, err := client.DownloadFile(ctx, f.FileKey)
+		if err != nil {
+			return fmt.Errorf("%s のダウンロードに失敗しました: %w", f.Name, err)
+		}
+		path := s.filePath(projectDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	// メタデータは最後に書き込み、存在するものだけを完成したスナップショットとして扱う
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir(projectDir), metaFile), data, 0644)
+}
+
+// List は保存済みのスナップショットを新しい順に返す
+func List(projectDir string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(Dir(projectDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := Load(projectDir, e.Name())
+		if err != nil {
+			// 作成途中で中断されたものなどは無視する
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Load は ID を指定してスナップショットを読み込む
+func Load(projectDir, id string) (*Snapshot, error) {
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("スナップショット ID が不正です: %s", id)
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(projectDir), id, metaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Customize == nil {
+		return nil, fmt.Errorf("スナップショット %s にカスタマイズ設定が含まれていません", id)
+	}
+	return &s, nil
+}
+
+// Restore は保存したファイルを再アップロードし、カスタマイズ設定をスナップショットの状態に戻す
+// 本番環境への反映は呼び出し元で行う
+func (s *Snapshot) Restore(ctx context.Context, projectDir string, client *kintone.Client) error {
+	fileKeys := map[string]string{}
+	for _, f := range s.Files() {
+		key, err := client.UploadFile(ctx, s.filePath(projectDir, f))
+		if err != nil {
+			return fmt.Errorf("%s の再アップロードに失敗しました: %w", f.Name, err)
+		}
+		fileKeys[f.FileKey] = key
+	}
+
+	return client.RestoreCustomize(ctx, s.AppID, s.Customize, fileKeys)
+}
+
+// uniqueID は作成日時から重複しない ID を生成する
+func uniqueID(projectDir string, t time.Time) string {
+	id := t.Format(idTimeFormat)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(Dir(projectDir), id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", t.Format(idTimeFormat), i)
+	}
+}
+
+// Prune は新しいものから keep 件を残して削除する
+// dev セッションが復元に使うスナップショットは件数に関わらず残す
+func Prune(projectDir string, keep int) error {
+	snapshots, err := List(projectDir)
+	if err != nil {
+		return err
+	}
+
+	var protected string
+	if session, err := LoadSession(projectDir); err == nil && session != nil {
+		protected = session.SnapshotID
+	}
+
+	for i := keep; i < len(snapshots); i++ {
+		if snapshots[i].ID == protected {
+			continue
+		}
+		if err := os.RemoveAll(snapshots[i].dir(projectDir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
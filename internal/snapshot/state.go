@@ -0,0 +1,100 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kintone/kcdev/internal/config"
+)
+
+const (
+	stateFile = "deploy-state.json"
+	stateDir  = "deploy-state"
+)
+
+// DeployState は kcdev deploy で最後に本番環境へ反映したバンドルの記録
+// dev ローダーが対象外のユーザーに適用するフォールバックとして使う
+type DeployState struct {
+	DeployedAt time.Time `json:"deployedAt"`
+	AppID      int       `json:"appId"`
+	// JS / CSS は .kcdev からの相対パス
+	JS  string `json:"js"`
+	CSS string `json:"css,omitempty"`
+}
+
+// RecordDeploy は本番環境に反映したバンドルのコピーを保存する
+// dist/ は後から再ビルドされるため、デプロイした時点の内容を .kcdev/deploy-state/ に残す
+func RecordDeploy(projectDir string, appID int, jsPath, cssPath string) error {
+	base := filepath.Join(projectDir, config.ConfigDir)
+	dir := filepath.Join(base, stateDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	state := &DeployState{DeployedAt: time.Now(), AppID: appID}
+
+	copyFile := func(src string) (string, error) {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return "", err
+		}
+		rel := filepath.Join(stateDir, filepath.Base(src))
+		return filepath.ToSlash(rel), os.WriteFile(filepath.Join(base, rel), data, 0644)
+	}
+
+	var err error
+	if state.JS, err = copyFile(jsPath); err != nil {
+		return err
+	}
+	if cssPath != "" {
+		if state.CSS, err = copyFile(cssPath); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(base, stateFile), data, 0644)
+}
+
+// LoadDeployState は最後のデプロイの記録を返す（無い場合は nil）
+func LoadDeployState(projectDir string) (*DeployState, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, config.ConfigDir, stateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state DeployState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ReadBundle は記録したバンドルの内容を返す
+func (s *DeployState) ReadBundle(projectDir string) (js, css string, err error) {
+	base := filepath.Join(projectDir, config.ConfigDir)
+
+	jsData, err := os.ReadFile(filepath.Join(base, filepath.FromSlash(s.JS)))
+	if err != nil {
+		return "", "", err
+	}
+	if s.CSS != "" {
+		cssData, err := os.ReadFile(filepath.Join(base, filepath.FromSlash(s.CSS)))
+		if err != nil {
+			return "", "", err
+		}
+		css = string(cssData)
+	}
+	return string(jsData), css, nil
+}
@@ -0,0 +1,304 @@
+package typegen
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kintone/kcdev/internal/kintone"
+)
+
+// App は型定義を生成するアプリのフォームの設定
+type App struct {
+	ID     int
+	Fields map[string]kintone.FieldProperty
+	Layout []kintone.LayoutItem
+	// Views は一覧（取得できなかった場合は nil）
+	Views []kintone.View
+	// Process はプロセス管理の設定（取得できなかった場合は nil）
+	Process *kintone.ProcessManagement
+	// Related は型を生成する関連アプリ（読み込めなかったアプリは含めない）
+	Related []*App
+	// Refs は関連アプリとして参照されている理由（例: ルックアップ: 顧客名）
+	Refs []string
+}
+
+// AppRef は関連アプリの ID と、参照しているフィールドの説明
+type AppRef struct {
+	ID   int
+	Refs []string
+}
+
+// RelatedAppRefs はルックアップ・関連レコード一覧（テーブル内を含む）の参照先のアプリをフォームの順に返す
+// 自身を参照する場合とアプリ ID が無い場合は含めない
+func (a *App) RelatedAppRefs() []AppRef {
+	var refs []AppRef
+	add := func(app, ref string) {
+		id, err := strconv.Atoi(app)
+		if err != nil || id == a.ID {
+			return
+		}
+		for i := range refs {
+			if refs[i].ID == id {
+				refs[i].Refs = append(refs[i].Refs, ref)
+				return
+			}
+		}
+		refs = append(refs, AppRef{ID: id, Refs: []string{ref}})
+	}
+
+	all := func(kintone.FieldProperty) bool { return true }
+	var walk func(fields []kintone.FieldProperty)
+	walk = func(fields []kintone.FieldProperty) {
+		for _, p := range fields {
+			if p.Lookup != nil {
+				add(p.Lookup.RelatedApp.App, "ルックアップ: "+fieldLabel(p))
+			}
+			if p.ReferenceTable != nil {
+				add(p.ReferenceTable.RelatedApp.App, "関連レコード一覧: "+fieldLabel(p))
+			}
+			if p.Type == "SUBTABLE" {
+				walk(a.orderFields(p.Fields, all))
+			}
+		}
+	}
+	walk(a.orderFields(a.Fields, all))
+	return refs
+}
+
+// namespaceName は関連アプリの型の名前空間・定数のキーを返す
+func namespaceName(id int) string {
+	return "App" + strconv.Itoa(id)
+}
+
+// hasProcess はプロセス管理が有効で、ステータスの型を生成できるかを返す
+func (a *App) hasProcess() bool {
+	return a.Process != nil && a.Process.Enable && len(a.Process.States) > 0
+}
+
+// systemFieldTypes はレコードの保存後に値が入るフィールドの種類（SavedFields に含める）
+var systemFieldTypes = []string{
+	"RECORD_NUMBER",
+	"CREATOR",
+	"CREATED_TIME",
+	"MODIFIER",
+	"UPDATED_TIME",
+	"STATUS",
+	"STATUS_ASSIGNEE",
+	"CATEGORY",
+}
+
+// nonRecordFieldTypes はレコードに値を持たないフィールドの種類
+var nonRecordFieldTypes = []string{"GROUP", "REFERENCE_TABLE", "LABEL", "SPACER", "HR"}
+
+func isSystemField(p kintone.FieldProperty) bool {
+	return containsString(systemFieldTypes, p.Type)
+}
+
+func isRecordField(p kintone.FieldProperty) bool {
+	return !containsString(nonRecordFieldTypes, p.Type)
+}
+
+// layoutCodes はレイアウトに配置されたフィールドコードをフォームの順に返す
+// テーブルとグループは自身のコードの後に中のフィールドを続ける
+func layoutCodes(items []kintone.LayoutItem) []string {
+	var codes []string
+	for _, item := range items {
+		if item.Code != "" {
+			codes = append(codes, item.Code)
+		}
+		for _, f := range item.Fields {
+			if f.Code != "" {
+				codes = append(codes, f.Code)
+			}
+		}
+		codes = append(codes, layoutCodes(item.Layout)...)
+	}
+	return codes
+}
+
+// orderFields は properties をレイアウトの順に並べる。レイアウトに無いフィールドはコード順で後に続ける
+func (a *App) orderFields(properties map[string]kintone.FieldProperty, include func(kintone.FieldProperty) bool) []kintone.FieldProperty {
+	var fields []kintone.FieldProperty
+	seen := make(map[string]bool)
+	for _, code := range layoutCodes(a.Layout) {
+		p, ok := properties[code]
+		if !ok || seen[code] || !include(p) {
+			continue
+		}
+		seen[code] = true
+		fields = append(fields, p)
+	}
+
+	var rest []string
+	for code, p := range properties {
+		if !seen[code] && include(p) {
+			rest = append(rest, code)
+		}
+	}
+	sort.Strings(rest)
+	for _, code := range rest {
+		fields = append(fields, properties[code])
+	}
+	return fields
+}
+
+// SpaceElementIDs はフォームに配置されたスペースの要素 ID をフォームの順に返す
+func (a *App) SpaceElementIDs() []string {
+	var ids []string
+	var walk func(items []kintone.LayoutItem)
+	walk = func(items []kintone.LayoutItem) {
+		for _, item := range items {
+			for _, f := range item.Fields {
+				if f.Type == "SPACER" && f.ElementID != "" && !containsString(ids, f.ElementID) {
+					ids = append(ids, f.ElementID)
+				}
+			}
+			walk(item.Layout)
+		}
+	}
+	walk(a.Layout)
+	return ids
+}
+
+// FormFields はレコードに値を持つフィールド（システムフィールドを除く）をフォームの順に返す
+func (a *App) FormFields() []kintone.FieldProperty {
+	return a.orderFields(a.Fields, func(p kintone.FieldProperty) bool {
+		return isRecordField(p) && !isSystemField(p)
+	})
+}
+
+// SubtableFields はテーブル内のフィールドをフォームの順に返す
+func (a *App) SubtableFields(table kintone.FieldProperty) []kintone.FieldProperty {
+	return a.orderFields(table.Fields, isRecordField)
+}
+
+// SystemFields は有効なシステムフィールド（レコード番号・作成者・ステータスなど）を決まった順で返す
+func (a *App) SystemFields() []kintone.FieldProperty {
+	var fields []kintone.FieldProperty
+	for _, t := range systemFieldTypes {
+		var codes []string
+		for code, p := range a.Fields {
+			if p.Type == t && p.IsEnabled() {
+				codes = append(codes, code)
+			}
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fields = append(fields, a.Fields[code])
+		}
+	}
+	return fields
+}
+
+// valueType はフィールドの value の型を TypeScript の型で返す（テーブルを除く）
+// JSDoc も同じ型の書き方を使う
+func valueType(p kintone.FieldProperty) string {
+	switch p.Type {
+	case "SINGLE_LINE_TEXT", "MULTI_LINE_TEXT", "RICH_TEXT", "NUMBER", "CALC", "LINK",
+		"DATE", "TIME", "DATETIME", "RECORD_NUMBER", "CREATED_TIME", "UPDATED_TIME", "STATUS":
+		return "string"
+	case "RADIO_BUTTON":
+		return optionUnion(p)
+	case "DROP_DOWN":
+		return optionUnion(p) + " | null"
+	case "CHECK_BOX", "MULTI_SELECT":
+		return "Array<" + optionUnion(p) + ">"
+	case "USER_SELECT", "ORGANIZATION_SELECT", "GROUP_SELECT", "STATUS_ASSIGNEE":
+		return "Array<{ code: string; name: string }>"
+	case "CREATOR", "MODIFIER":
+		return "{ code: string; name: string }"
+	case "FILE":
+		return "Array<{ contentType: string; fileKey: string; name: string; size: string }>"
+	case "CATEGORY":
+		return "string[]"
+	default:
+		return "unknown"
+	}
+}
+
+// optionUnion は選択肢の文字列リテラルの共用体を返す（選択肢が無い場合は string）
+func optionUnion(p kintone.FieldProperty) string {
+	return stringUnion(p.OptionLabels())
+}
+
+// stringUnion は文字列リテラルの共用体を返す（values が空の場合は string）
+func stringUnion(values []string) string {
+	if len(values) == 0 {
+		return "string"
+	}
+	literals := make([]string, len(values))
+	for i, v := range values {
+		literals[i] = jsString(v)
+	}
+	return strings.Join(literals, " | ")
+}
+
+func fieldLabel(p kintone.FieldProperty) string {
+	if p.Label == "" {
+		return p.Code
+	}
+	return p.Label
+}
+
+// fieldDoc はフィールドの JSDoc に書く説明（ラベルとルックアップの参照先）を返す
+func fieldDoc(p kintone.FieldProperty) string {
+	doc := fieldLabel(p)
+	if p.Lookup != nil && p.Lookup.RelatedApp.App != "" {
+		ref := "アプリ " + p.Lookup.RelatedApp.App
+		if p.Lookup.RelatedKeyField != "" {
+			ref += " の " + p.Lookup.RelatedKeyField
+		}
+		doc += "（ルックアップ: " + ref + "）"
+	}
+	return docText(doc)
+}
+
+// docText はコメントを閉じないよう */ を崩し、改行を空白にする
+func docText(s string) string {
+	s = strings.ReplaceAll(s, "*/", "*\\/")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// jsString は JavaScript の文字列リテラルを返す
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// propertyKey はオブジェクトのキーを返す。識別子として使えないフィールドコードは文字列にする
+func propertyKey(code string) string {
+	if isIdentifier(code) {
+		return code
+	}
+	return jsString(code)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+		case i > 0 && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
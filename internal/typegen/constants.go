@@ -0,0 +1,138 @@
+package typegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constants はフィールドコード・スペースの要素 ID・一覧・プロセス管理・アプリ ID の定数のモジュール（src/generated/app.ts / app.js）の内容を返す
+// 文字列を直接書かずに定数を使うことで、フィールドコードの誤りを型チェックで検出できる
+func Constants(app *App, typescript bool) string {
+	c := constWriter{typescript: typescript}
+	fmt.Fprintf(&c.b, "// このファイルは kcdev types で自動生成されます（アプリ ID: %d）\n", app.ID)
+	c.b.WriteString("// 注意: このファイルは手動で編集しないでください\n\n")
+
+	c.b.WriteString("/** アプリ ID */\n")
+	fmt.Fprintf(&c.b, "export const APP_ID = %d;\n\n", app.ID)
+
+	// このアプリと関連アプリの ID（kintone.types.SavedFieldsByApp のキー）
+	c.begin("このアプリと関連アプリの ID", "appIds")
+	c.entry("  ", "このアプリ", namespaceName(app.ID), strconv.Itoa(app.ID))
+	for _, r := range app.Related {
+		c.entry("  ", relatedDoc(r), namespaceName(r.ID), strconv.Itoa(r.ID))
+	}
+	c.end("appIds", "")
+
+	// フィールドコード（システムフィールドを含む）
+	fields := append(app.FormFields(), app.SystemFields()...)
+	c.begin("フィールドコード", "FIELD_CODE")
+	for _, p := range fields {
+		c.entry("  ", fieldDoc(p), propertyKey(p.Code), jsString(p.Code))
+	}
+	c.end("FIELD_CODE", "FieldCode")
+
+	// テーブル内のフィールドコード
+	c.begin("テーブル内のフィールドコード（テーブルのフィールドコードごと）", "SUBTABLE_FIELD_CODE")
+	for _, p := range app.FormFields() {
+		if p.Type != "SUBTABLE" {
+			continue
+		}
+		fmt.Fprintf(&c.b, "  /** %s */\n", fieldDoc(p))
+		fmt.Fprintf(&c.b, "  %s: {\n", propertyKey(p.Code))
+		for _, f := range app.SubtableFields(p) {
+			c.entry("    ", fieldDoc(f), propertyKey(f.Code), jsString(f.Code))
+		}
+		c.b.WriteString("  },\n")
+	}
+	c.end("SUBTABLE_FIELD_CODE", "")
+
+	// スペースの要素 ID（kintone.app.record.getSpaceElement に渡す）
+	c.begin("スペースの要素 ID", "SPACE_ID")
+	for _, id := range app.SpaceElementIDs() {
+		c.entry("  ", "スペース "+docText(id), propertyKey(id), jsString(id))
+	}
+	c.end("SPACE_ID", "SpaceId")
+
+	// 一覧の ID と名前（event.viewId / event.viewName との比較に使う）
+	c.begin("一覧", "VIEW")
+	for _, v := range app.Views {
+		doc := docText(fmt.Sprintf("%s（一覧 ID: %s）", v.Name, v.ID))
+		id := jsString(v.ID)
+		if _, err := strconv.Atoi(v.ID); err == nil {
+			id = v.ID // event.viewId は数値
+		}
+		c.entry("  ", doc, propertyKey(v.Name), fmt.Sprintf("{ id: %s, name: %s }", id, jsString(v.Name)))
+	}
+	c.end("VIEW", "")
+
+	// プロセス管理のステータス・アクション・遷移（プロセス管理が有効な場合）
+	if app.hasProcess() {
+		c.begin("プロセス管理のステータス", "STATUS")
+		for _, name := range app.Process.StateNames() {
+			c.entry("  ", "ステータス "+docText(name), propertyKey(name), jsString(name))
+		}
+		c.end("STATUS", "Status")
+
+		c.begin("プロセス管理のアクション", "ACTION")
+		for _, name := range app.Process.ActionNames() {
+			c.entry("  ", "アクション "+docText(name), propertyKey(name), jsString(name))
+		}
+		c.end("ACTION", "Action")
+
+		c.b.WriteString("/** アクションによるステータスの遷移 */\n")
+		if c.typescript {
+			c.b.WriteString("export const TRANSITIONS = [\n")
+		} else {
+			c.b.WriteString("export const TRANSITIONS = /** @type {const} */ ([\n")
+		}
+		for _, a := range app.Process.Actions {
+			fmt.Fprintf(&c.b, "  /** %s */\n", docText(a.From+" → "+a.To))
+			fmt.Fprintf(&c.b, "  { action: %s, from: %s, to: %s },\n", jsString(a.Name), jsString(a.From), jsString(a.To))
+		}
+		if c.typescript {
+			c.b.WriteString("] as const;\n\n")
+		} else {
+			c.b.WriteString("]);\n\n")
+		}
+	}
+
+	return strings.TrimSuffix(c.b.String(), "\n")
+}
+
+// constWriter は TypeScript（as const）と JavaScript（JSDoc の @type {const}）の定数を書き出す
+type constWriter struct {
+	b          strings.Builder
+	typescript bool
+}
+
+func (c *constWriter) begin(doc, name string) {
+	fmt.Fprintf(&c.b, "/** %s */\n", doc)
+	if c.typescript {
+		fmt.Fprintf(&c.b, "export const %s = {\n", name)
+	} else {
+		fmt.Fprintf(&c.b, "export const %s = /** @type {const} */ ({\n", name)
+	}
+}
+
+func (c *constWriter) entry(indent, doc, key, value string) {
+	fmt.Fprintf(&c.b, "%s/** %s */\n", indent, doc)
+	fmt.Fprintf(&c.b, "%s%s: %s,\n", indent, key, value)
+}
+
+// end はオブジェクトを閉じ、typeName が空でなければ値の共用体の型を宣言する
+func (c *constWriter) end(name, typeName string) {
+	if c.typescript {
+		c.b.WriteString("} as const;\n\n")
+	} else {
+		c.b.WriteString("});\n\n")
+	}
+	if typeName == "" {
+		return
+	}
+	if c.typescript {
+		fmt.Fprintf(&c.b, "export type %s = (typeof %s)[keyof typeof %s];\n\n", typeName, name, name)
+	} else {
+		fmt.Fprintf(&c.b, "/** @typedef {(typeof %s)[keyof typeof %s]} %s */\n\n", name, name, typeName)
+	}
+}
@@ -0,0 +1,55 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSDoc は JavaScript プロジェクトで使う @typedef のモジュール（src/types/fields.js）の内容を返す
+// 型の本体は TypeScript と同じ kintone.d.ts に宣言し、jsconfig.json から読み込む
+func JSDoc(app *App) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// このファイルは kcdev types で自動生成されます（アプリ ID: %d）\n", app.ID)
+	b.WriteString("// 注意: このファイルは手動で編集しないでください\n")
+	b.WriteString("//\n")
+	b.WriteString("// 使い方:\n")
+	b.WriteString("//   /** @type {import(\"./types/fields\").SavedFields} */\n")
+	b.WriteString("//   const record = event.record;\n\n")
+
+	b.WriteString("/**\n")
+	b.WriteString(" * レコードのフィールド（システムフィールドを除く）\n")
+	b.WriteString(" * @typedef {kintone.types.Fields} Fields\n")
+	b.WriteString(" */\n\n")
+
+	b.WriteString("/**\n")
+	b.WriteString(" * 保存済みのレコードのフィールド（レコード ID・リビジョン・システムフィールドを含む）\n")
+	b.WriteString(" * @typedef {kintone.types.SavedFields} SavedFields\n")
+	b.WriteString(" */\n\n")
+
+	if app.hasProcess() {
+		b.WriteString("/** @typedef {kintone.types.StatusName} StatusName プロセス管理のステータス */\n")
+		b.WriteString("/** @typedef {kintone.types.ActionName} ActionName プロセス管理のアクション */\n")
+		b.WriteString("/** @typedef {kintone.types.ProcessProceedEvent} ProcessProceedEvent プロセス管理のアクションを実行したときのイベント */\n\n")
+	}
+
+	for _, r := range app.Related {
+		fmt.Fprintf(&b, "/** @typedef {kintone.types.related.%s.SavedFields} %sSavedFields 関連アプリのレコード: %s */\n", namespaceName(r.ID), namespaceName(r.ID), relatedDoc(r))
+	}
+	if len(app.Related) > 0 {
+		b.WriteString("\n")
+	}
+
+	// テーブルの行は record.テーブル.value.forEach((row) => ...) の row に使う
+	for _, p := range app.FormFields() {
+		if p.Type != "SUBTABLE" || !isIdentifier(p.Code) {
+			continue
+		}
+		b.WriteString("/**\n")
+		fmt.Fprintf(&b, " * テーブル「%s」の行\n", fieldDoc(p))
+		fmt.Fprintf(&b, " * @typedef {kintone.types.Fields[%s][\"value\"][number]} %sRow\n", jsString(p.Code), p.Code)
+		b.WriteString(" */\n\n")
+	}
+
+	b.WriteString("export {};\n")
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package typegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/kintone/kcdev/internal/kintone"
+)
+
+var update = flag.Bool("update", false, "testdata の期待値を生成結果で更新する")
+
+// assertGolden は got を testdata/name の内容と比較する（-update で書き換える）
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s を読み込めません（go test -update で生成してください）: %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("%s と一致しません\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func options(labels ...string) map[string]kintone.FieldOption {
+	m := make(map[string]kintone.FieldOption, len(labels))
+	for i, l := range labels {
+		m[l] = kintone.FieldOption{Label: l, Index: strconv.Itoa(i)}
+	}
+	return m
+}
+
+func enabled(b bool) *bool {
+	return &b
+}
+
+// testApp は型定義の生成に使うアプリ（フィールドの種類・テーブル・グループ・ルックアップ・
+// 関連レコード一覧・一覧・プロセス管理・関連アプリを含む）
+func testApp() *App {
+	return &App{
+		ID: 10,
+		Fields: map[string]kintone.FieldProperty{
+			"件名": {Type: "SINGLE_LINE_TEXT", Code: "件名", Label: "件名", Required: true},
+			"優先度": {Type: "RADIO_BUTTON", Code: "優先度", Label: "優先度", Options: map[string]kintone.FieldOption{
+				"低": {Label: "低", Index: "10"},
+				"中": {Label: "中", Index: "2"},
+				"高": {Label: "高", Index: "0"},
+			}},
+			"分類":          {Type: "DROP_DOWN", Code: "分類", Label: "分類", Options: options("問い合わせ", "不具合")},
+			"タグ":          {Type: "CHECK_BOX", Code: "タグ", Label: "タグ", Options: options(`"引用"`, "その他")},
+			"担当者":         {Type: "USER_SELECT", Code: "担当者", Label: "担当者"},
+			"添付":          {Type: "FILE", Code: "添付", Label: "添付ファイル"},
+			"price-total": {Type: "CALC", Code: "price-total", Label: "合計 */ 金額\n（税込）"},
+			"顧客": {Type: "SINGLE_LINE_TEXT", Code: "顧客", Label: "顧客名", Lookup: &kintone.Lookup{
+				RelatedApp:      kintone.RelatedApp{App: "20"},
+				RelatedKeyField: "顧客コード",
+			}},
+			"対応履歴": {Type: "REFERENCE_TABLE", Code: "対応履歴", Label: "対応履歴", ReferenceTable: &kintone.ReferenceTable{
+				RelatedApp: kintone.RelatedApp{App: "30"},
+			}},
+			"グループ": {Type: "GROUP", Code: "グループ", Label: "詳細"},
+			"メモ":   {Type: "MULTI_LINE_TEXT", Code: "メモ", Label: "メモ"},
+			"明細": {Type: "SUBTABLE", Code: "明細", Fields: map[string]kintone.FieldProperty{
+				"品名": {Type: "SINGLE_LINE_TEXT", Code: "品名", Label: "品名"},
+				"数量": {Type: "NUMBER", Code: "数量", Label: "数量"},
+				"商品": {Type: "NUMBER", Code: "商品", Label: "商品", Lookup: &kintone.Lookup{
+					RelatedApp: kintone.RelatedApp{App: "20"},
+				}},
+			}},
+			"未配置":    {Type: "DATE", Code: "未配置", Label: "未配置"},
+			"レコード番号": {Type: "RECORD_NUMBER", Code: "レコード番号", Label: "レコード番号"},
+			"作成者":    {Type: "CREATOR", Code: "作成者", Label: "作成者"},
+			"更新日時":   {Type: "UPDATED_TIME", Code: "更新日時", Label: "更新日時"},
+			"ステータス":  {Type: "STATUS", Code: "ステータス", Label: "ステータス", Enabled: enabled(true)},
+			"作業者":    {Type: "STATUS_ASSIGNEE", Code: "作業者", Label: "作業者", Enabled: enabled(true)},
+			"カテゴリー":  {Type: "CATEGORY", Code: "カテゴリー", Label: "カテゴリー", Enabled: enabled(false)},
+		},
+		Layout: []kintone.LayoutItem{
+			{Type: "ROW", Fields: []kintone.LayoutField{
+				{Type: "SINGLE_LINE_TEXT", Code: "件名"},
+				{Type: "LABEL", Label: "説明"},
+				{Type: "RADIO_BUTTON", Code: "優先度"},
+			}},
+			{Type: "ROW", Fields: []kintone.LayoutField{
+				{Type: "DROP_DOWN", Code: "分類"},
+				{Type: "CHECK_BOX", Code: "タグ"},
+				{Type: "SPACER", ElementID: "header-space"},
+			}},
+			{Type: "ROW", Fields: []kintone.LayoutField{
+				{Type: "SINGLE_LINE_TEXT", Code: "顧客"},
+				{Type: "USER_SELECT", Code: "担当者"},
+				{Type: "CALC", Code: "price-total"},
+			}},
+			{Type: "SUBTABLE", Code: "明細", Fields: []kintone.LayoutField{
+				{Type: "NUMBER", Code: "商品"},
+				{Type: "SINGLE_LINE_TEXT", Code: "品名"},
+				{Type: "NUMBER", Code: "数量"},
+			}},
+			{Type: "GROUP", Code: "グループ", Layout: []kintone.LayoutItem{
+				{Type: "ROW", Fields: []kintone.LayoutField{
+					{Type: "MULTI_LINE_TEXT", Code: "メモ"},
+					{Type: "FILE", Code: "添付"},
+					{Type: "SPACER", ElementID: "footer space"},
+				}},
+			}},
+			{Type: "ROW", Fields: []kintone.LayoutField{
+				{Type: "REFERENCE_TABLE", Code: "対応履歴"},
+				{Type: "SPACER", ElementID: "header-space"},
+				{Type: "SPACER"},
+			}},
+		},
+		Views: []kintone.View{
+			{ID: "5519903", Name: "すべて", Type: "LIST", Index: "0"},
+			{ID: "20", Name: "未対応の一覧", Type: "LIST", Index: "1"},
+			{ID: "custom-view", Name: "カレンダー-表示", Type: "CALENDAR", Index: "2"},
+		},
+		Process: &kintone.ProcessManagement{
+			Enable: true,
+			States: map[string]kintone.ProcessState{
+				"未処理": {Name: "未処理", Index: "0"},
+				"処理中": {Name: "処理中", Index: "1"},
+				"完了":  {Name: "完了", Index: "2"},
+			},
+			Actions: []kintone.ProcessAction{
+				{Name: "処理開始", From: "未処理", To: "処理中"},
+				{Name: "完了する", From: "処理中", To: "完了"},
+				{Name: "差し戻す", From: "処理中", To: "未処理"},
+				{Name: "差し戻す", From: "完了", To: "未処理"},
+			},
+		},
+		Related: []*App{
+			{
+				ID: 20,
+				Fields: map[string]kintone.FieldProperty{
+					"顧客コード":  {Type: "SINGLE_LINE_TEXT", Code: "顧客コード", Label: "顧客コード"},
+					"会社名":    {Type: "SINGLE_LINE_TEXT", Code: "会社名", Label: "会社名"},
+					"レコード番号": {Type: "RECORD_NUMBER", Code: "レコード番号", Label: "レコード番号"},
+				},
+				Refs: []string{"ルックアップ: 顧客名", "ルックアップ: 商品"},
+			},
+		},
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	assertGolden(t, "kintone.d.ts.golden", TypeScript(testApp()))
+}
+
+func TestTypeScriptWithoutProcessAndRelated(t *testing.T) {
+	app := testApp()
+	app.Process.Enable = false
+	app.Related = nil
+	assertGolden(t, "kintone-minimal.d.ts.golden", TypeScript(app))
+}
+
+func TestJSDoc(t *testing.T) {
+	app := testApp()
+	// 識別子として使えないテーブルのフィールドコードは行の型を省略する
+	app.Fields["line-items"] = kintone.FieldProperty{Type: "SUBTABLE", Code: "line-items", Fields: map[string]kintone.FieldProperty{
+		"sku": {Type: "SINGLE_LINE_TEXT", Code: "sku", Label: "SKU"},
+	}}
+	assertGolden(t, "fields.js.golden", JSDoc(app))
+}
+
+func TestConstants(t *testing.T) {
+	assertGolden(t, "app.ts.golden", Constants(testApp(), true))
+	assertGolden(t, "app.js.golden", Constants(testApp(), false))
+}
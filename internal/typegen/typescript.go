@@ -0,0 +1,137 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kintone/kcdev/internal/kintone"
+)
+
+// TypeScript は kintone.types.Fields / SavedFields を宣言する kintone.d.ts の内容を返す
+// @kintone/dts-gen と同じ名前で宣言するため、既存のコードはそのまま使える
+// 関連アプリは kintone.types.related.App{ID} に同じ形で宣言する
+func TypeScript(app *App) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// このファイルは kcdev types で自動生成されます（アプリ ID: %d）\n", app.ID)
+	b.WriteString("// 注意: このファイルは手動で編集しないでください\n\n")
+
+	b.WriteString("declare namespace kintone.types {\n")
+	writeTSRecordTypes(&b, app, "  ")
+	if app.hasProcess() {
+		writeTSProcess(&b, app)
+	}
+
+	if len(app.Related) > 0 {
+		b.WriteString("\n  /** 関連アプリ（ルックアップ・関連レコード一覧の参照先など）のレコードの型 */\n")
+		b.WriteString("  namespace related {\n")
+		for i, r := range app.Related {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "    /** %s */\n", relatedDoc(r))
+			fmt.Fprintf(&b, "    namespace %s {\n", namespaceName(r.ID))
+			writeTSRecordTypes(&b, r, "      ")
+			b.WriteString("    }\n")
+		}
+		b.WriteString("  }\n")
+	}
+
+	// kintone.api の戻り値などに使う、アプリ ID からレコードの型への対応
+	b.WriteString("\n  /** アプリ ID ごとの保存済みのレコードのフィールド */\n")
+	b.WriteString("  interface SavedFieldsByApp {\n")
+	fmt.Fprintf(&b, "    %d: SavedFields;\n", app.ID)
+	for _, r := range app.Related {
+		fmt.Fprintf(&b, "    %d: related.%s.SavedFields;\n", r.ID, namespaceName(r.ID))
+	}
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeTSRecordTypes はアプリの Fields / SavedFields を書き出す
+func writeTSRecordTypes(b *strings.Builder, app *App, indent string) {
+	fmt.Fprintf(b, "%s/** レコードのフィールド（システムフィールドを除く） */\n", indent)
+	fmt.Fprintf(b, "%sinterface Fields {\n", indent)
+	for _, p := range app.FormFields() {
+		writeTSField(b, app, p, indent+"  ")
+	}
+	fmt.Fprintf(b, "%s}\n\n", indent)
+
+	fmt.Fprintf(b, "%s/** 保存済みのレコードのフィールド（レコード ID・リビジョン・システムフィールドを含む） */\n", indent)
+	fmt.Fprintf(b, "%sinterface SavedFields extends Fields {\n", indent)
+	fmt.Fprintf(b, "%s  $id: { type: \"__ID__\"; value: string };\n", indent)
+	fmt.Fprintf(b, "%s  $revision: { type: \"__REVISION__\"; value: string };\n", indent)
+	for _, p := range app.SystemFields() {
+		writeTSField(b, app, p, indent+"  ")
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// relatedDoc は関連アプリの JSDoc に書く説明（アプリ ID と参照しているフィールド）を返す
+func relatedDoc(app *App) string {
+	doc := fmt.Sprintf("アプリ %d", app.ID)
+	if len(app.Refs) > 0 {
+		doc += "（" + strings.Join(app.Refs, "、") + "）"
+	}
+	return docText(doc)
+}
+
+// writeTSProcess はプロセス管理のステータス・アクションの型と、
+// アクションごとの遷移で絞り込める app.record.detail.process.proceed のイベントの型を書き出す
+func writeTSProcess(b *strings.Builder, app *App) {
+	b.WriteString("\n  /** プロセス管理のステータス */\n")
+	fmt.Fprintf(b, "  type StatusName = %s;\n\n", stringUnion(app.Process.StateNames()))
+	b.WriteString("  /** プロセス管理のアクション */\n")
+	fmt.Fprintf(b, "  type ActionName = %s;\n\n", stringUnion(app.Process.ActionNames()))
+
+	b.WriteString("  /** アクションによるステータスの遷移 */\n")
+	b.WriteString("  type ProcessTransition =\n")
+	if len(app.Process.Actions) == 0 {
+		b.WriteString("    never;\n\n")
+	}
+	for i, a := range app.Process.Actions {
+		end := ""
+		if i == len(app.Process.Actions)-1 {
+			end = ";"
+		}
+		fmt.Fprintf(b, "    | { action: { value: %s }; status: { value: %s }; nextStatus: { value: %s } }%s\n",
+			jsString(a.Name), jsString(a.From), jsString(a.To), end)
+	}
+	if len(app.Process.Actions) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("  /** プロセス管理のアクションを実行したときのイベント（action.value で遷移を絞り込める） */\n")
+	b.WriteString("  type ProcessProceedEvent = {\n")
+	b.WriteString("    type: \"app.record.detail.process.proceed\" | \"mobile.app.record.detail.process.proceed\";\n")
+	b.WriteString("    appId: number;\n")
+	b.WriteString("    recordId: number;\n")
+	b.WriteString("    record: SavedFields;\n")
+	b.WriteString("  } & ProcessTransition;\n")
+}
+
+// writeTSField はフィールドの型を JSDoc 付きで 1 行（テーブルは複数行）書き出す
+func writeTSField(b *strings.Builder, app *App, p kintone.FieldProperty, indent string) {
+	fmt.Fprintf(b, "%s/** %s */\n", indent, fieldDoc(p))
+	key := propertyKey(p.Code)
+	if p.Type != "SUBTABLE" {
+		vt := valueType(p)
+		if p.Type == "STATUS" && app.hasProcess() {
+			vt = "StatusName"
+		}
+		fmt.Fprintf(b, "%s%s: { type: %s; value: %s };\n", indent, key, jsString(p.Type), vt)
+		return
+	}
+
+	fmt.Fprintf(b, "%s%s: {\n", indent, key)
+	fmt.Fprintf(b, "%s  type: \"SUBTABLE\";\n", indent)
+	fmt.Fprintf(b, "%s  value: Array<{\n", indent)
+	fmt.Fprintf(b, "%s    id: string;\n", indent)
+	fmt.Fprintf(b, "%s    value: {\n", indent)
+	for _, f := range app.SubtableFields(p) {
+		writeTSField(b, app, f, indent+"      ")
+	}
+	fmt.Fprintf(b, "%s    };\n", indent)
+	fmt.Fprintf(b, "%s  }>;\n", indent)
+	fmt.Fprintf(b, "%s};\n", indent)
+}
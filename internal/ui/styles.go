@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/charmbracelet/huh"
@@ -63,6 +64,22 @@ func Info(msg string) {
 	fmt.Println(InfoStyle.Render(IconInfo) + " " + msg)
 }
 
+// BrowserLog はブラウザから転送されたログをレベルに応じた色で表示
+func BrowserLog(level, source, msg string) {
+	style := lipgloss.NewStyle()
+	switch level {
+	case "error":
+		style = ErrorStyle
+	case "warn":
+		style = WarnStyle
+	case "info":
+		style = InfoStyle
+	case "debug":
+		style = MutedStyle
+	}
+	fmt.Println(MutedStyle.Render("[browser]") + " " + style.Render(fmt.Sprintf("%-5s", level)) + " " + MutedStyle.Render(source) + " " + style.Render(msg))
+}
+
 // Title はタイトルを表示
 func Title(msg string) {
 	fmt.Println(TitleStyle.Render(msg))
@@ -96,6 +113,24 @@ func SpinnerWithResult(title string, action func() error) error {
 	return actionErr
 }
 
+// SpinnerContext は ctx を渡してスピナーを実行し、エラーを返す
+// ctx がキャンセルされても action の終了を待ってから戻るため、action は ctx を監視して速やかに終了すること
+func SpinnerContext(ctx context.Context, title string, action func(ctx context.Context) error) error {
+	done, stop := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- action(ctx)
+		stop()
+	}()
+
+	// スピナーは action の完了（done のキャンセル）まで表示し続ける
+	_ = spinner.New().
+		Title(title).
+		Context(done).
+		Run()
+	return <-result
+}
+
 // NewForm はカスタムテーマ付きのフォームを作成
 func NewForm(groups ...*huh.Group) *huh.Form {
 	return huh.NewForm(groups...).